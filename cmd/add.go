@@ -26,7 +26,7 @@ func newAddCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			tok, err := tokenFromStore()
+			tok, err := tokenFromStore(c.Context())
 			if err != nil {
 				return err
 			}
@@ -34,6 +34,7 @@ func newAddCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			noteOffline()
 			wd, err := os.Getwd()
 			if err != nil {
 				return err
@@ -75,6 +76,9 @@ func newAddCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := c.Context().Err(); err != nil {
+				return rollbackInstall(mgr, installed, err)
+			}
 			if hasExisting {
 				oldPath := existing.Path
 				if oldPath == "" {
@@ -103,7 +107,7 @@ func newAddCmd() *cobra.Command {
 				mf.Installed = append(mf.Installed, entry)
 			}
 			if err := store.Save(mf); err != nil {
-				return err
+				return rollbackInstall(mgr, installed, err)
 			}
 			if ctx.Mode == output.ModeJSON {
 				return output.PrintJSON(entry)
@@ -125,3 +129,12 @@ func firstNonEmpty(primary, fallback string) string {
 	}
 	return fallback
 }
+
+// rollbackInstall undoes an install that the manifest will not record and
+// returns cause.
+func rollbackInstall(mgr *install.Manager, res install.InstallResult, cause error) error {
+	if err := mgr.Rollback(res); err != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, err)
+	}
+	return cause
+}
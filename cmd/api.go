@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newAPICmd() *cobra.Command {
+	var method string
+	var fields, typedFields []string
+	var input string
+	var paginate bool
+	var include bool
+
+	cmd := &cobra.Command{
+		Use:   "api <path>",
+		Short: "Make an authenticated request to the CodeMint API",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("api expects exactly one path, for example /api/auth/me")
+			}
+			target, err := url.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid path %q: %w", args[0], err)
+			}
+			if target.IsAbs() {
+				return fmt.Errorf("pass a path such as /api/auth/me, not a full URL; use --base-url to change the server")
+			}
+			params, err := parseFields(fields, typedFields)
+			if err != nil {
+				return err
+			}
+			var body []byte
+			if input != "" {
+				if len(params) > 0 {
+					return fmt.Errorf("use either --input or --field, not both")
+				}
+				body, err = readInput(input)
+				if err != nil {
+					return err
+				}
+			}
+			if method == "" {
+				method = http.MethodGet
+				if len(params) > 0 || body != nil {
+					method = http.MethodPost
+				}
+			}
+			method = strings.ToUpper(method)
+			if len(params) > 0 {
+				if method == http.MethodGet || method == http.MethodHead {
+					q := target.Query()
+					for k, v := range params {
+						q.Set(k, queryValue(v))
+					}
+					target.RawQuery = q.Encode()
+				} else if body, err = json.Marshal(params); err != nil {
+					return err
+				}
+			}
+			if paginate && method != http.MethodGet {
+				return fmt.Errorf("--paginate only works with GET requests")
+			}
+
+			tok, err := tokenFromStore(c.Context())
+			if err != nil {
+				return err
+			}
+			req := api.RawRequest{Method: method, Path: target.String(), Token: tok, Body: body}
+			var resp *api.RawResponse
+			if paginate {
+				resp, err = paginateRaw(c.Context(), req, target)
+			} else {
+				resp, err = ctx.Client.Raw(c.Context(), req)
+			}
+			if err != nil {
+				return err
+			}
+			if include {
+				printResponseHeaders(resp)
+			}
+			if ctx.Mode == output.ModeJSON && len(resp.Body) > 0 {
+				var v any
+				if err := json.Unmarshal(resp.Body, &v); err == nil {
+					return output.PrintJSON(v)
+				}
+			}
+			if _, err := os.Stdout.Write(resp.Body); err != nil {
+				return err
+			}
+			if len(resp.Body) > 0 && resp.Body[len(resp.Body)-1] != '\n' {
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&method, "method", "X", "", "HTTP method (default GET, or POST when a body is given)")
+	cmd.Flags().StringArrayVarP(&fields, "field", "f", nil, "add a key=value string field (query for GET, JSON body otherwise); repeatable")
+	cmd.Flags().StringArrayVarP(&typedFields, "typed-field", "F", nil, "add a key=value field where true, false, null and integers keep their JSON type and @file reads the value from a file (@- for stdin); repeatable")
+	cmd.Flags().StringVar(&input, "input", "", "file to send as the request body, or - for stdin")
+	cmd.Flags().BoolVar(&paginate, "paginate", false, "follow the next link (or page/limit/total) and merge the data arrays of every page")
+	cmd.Flags().BoolVarP(&include, "include", "i", false, "print the response status line and headers")
+	return cmd
+}
+
+// parseFields merges -f string fields and -F typed fields into one set of
+// request parameters.
+func parseFields(raw, typed []string) (map[string]any, error) {
+	params := make(map[string]any, len(raw)+len(typed))
+	for _, f := range raw {
+		k, v, err := splitField(f)
+		if err != nil {
+			return nil, err
+		}
+		params[k] = v
+	}
+	for _, f := range typed {
+		k, v, err := splitField(f)
+		if err != nil {
+			return nil, err
+		}
+		params[k], err = typedValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", k, err)
+		}
+	}
+	return params, nil
+}
+
+func splitField(f string) (string, string, error) {
+	k, v, ok := strings.Cut(f, "=")
+	if !ok || k == "" {
+		return "", "", fmt.Errorf("invalid field %q: expected key=value", f)
+	}
+	return k, v, nil
+}
+
+// typedValue converts a -F value the way gh api does.
+func typedValue(v string) (any, error) {
+	switch v {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n, nil
+	}
+	if path, ok := strings.CutPrefix(v, "@"); ok {
+		b, err := readInput(path)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	return v, nil
+}
+
+func queryValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func printResponseHeaders(resp *api.RawResponse) {
+	fmt.Printf("%s %d %s\n", firstNonEmpty(resp.Proto, "HTTP/1.1"), resp.Status, http.StatusText(resp.Status))
+	keys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range resp.Header[k] {
+			fmt.Printf("%s: %s\n", k, v)
+		}
+	}
+	fmt.Println()
+}
+
+// paginateRaw walks list responses ({"data": [...]}) and returns one
+// response whose data holds every page. It follows a Link rel="next" header
+// or a "next" field when the server sends one, and otherwise counts pages
+// with page/limit/total.
+func paginateRaw(reqCtx context.Context, req api.RawRequest, target *url.URL) (*api.RawResponse, error) {
+	type page struct {
+		Data  []json.RawMessage `json:"data"`
+		Page  int               `json:"page"`
+		Limit int               `json:"limit"`
+		Total int               `json:"total"`
+		Next  string            `json:"next"`
+	}
+	q := target.Query()
+	current, _ := strconv.Atoi(q.Get("page"))
+	if current <= 0 {
+		current = 1
+	}
+	var first *api.RawResponse
+	all := make([]json.RawMessage, 0)
+	total := 0
+	// linked is set once the server has handed out a next link; from then
+	// on a page without one is the last.
+	linked := false
+	for n := 0; n < 1000; n++ {
+		resp, err := ctx.Client.Raw(reqCtx, req)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = resp
+		}
+		var p page
+		if err := json.Unmarshal(resp.Body, &p); err != nil || p.Data == nil {
+			return nil, fmt.Errorf("--paginate: %s did not return a data array", target.Path)
+		}
+		all = append(all, p.Data...)
+		if p.Total > 0 {
+			total = p.Total
+		}
+		if next := nextLink(resp.Header, p.Next); next != "" {
+			req.Path = next
+			linked = true
+			continue
+		}
+		if linked {
+			break
+		}
+		limit := p.Limit
+		if limit <= 0 {
+			limit = len(p.Data)
+		}
+		if len(p.Data) == 0 || len(p.Data) < limit || (p.Total > 0 && len(all) >= p.Total) {
+			break
+		}
+		current++
+		q.Set("page", strconv.Itoa(current))
+		target.RawQuery = q.Encode()
+		req.Path = target.String()
+	}
+	body, err := json.Marshal(map[string]any{"data": all, "page": 1, "limit": len(all), "total": total})
+	if err != nil {
+		return nil, err
+	}
+	first.Body = body
+	return first, nil
+}
+
+// nextLink returns the path of the next page from a Link header or a body
+// "next" field, or "" on the last page.
+func nextLink(h http.Header, bodyNext string) string {
+	next := bodyNext
+	for _, part := range strings.Split(h.Get("Link"), ",") {
+		ref, params, ok := strings.Cut(part, ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			next = strings.Trim(strings.TrimSpace(ref), "<>")
+			break
+		}
+	}
+	if next == "" {
+		return ""
+	}
+	u, err := url.Parse(next)
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
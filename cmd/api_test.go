@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/auth"
+	"github.com/codemint/codemint-cli/internal/output"
+)
+
+type apiCall struct {
+	method, query, auth string
+	body                map[string]any
+}
+
+// runAPI runs `codemint api` against handler and returns its stdout, the
+// requests the server saw and the command's error.
+func runAPI(t *testing.T, handler http.HandlerFunc, args ...string) (string, []apiCall, error) {
+	t.Helper()
+	var calls []apiCall
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := apiCall{method: r.Method, query: r.URL.RawQuery, auth: r.Header.Get("Authorization")}
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			_ = json.Unmarshal(b, &call.body)
+		}
+		calls = append(calls, call)
+		handler(w, r)
+	}))
+	defer ts.Close()
+
+	t.Setenv(auth.EnvToken, "test-token")
+	t.Setenv(auth.EnvTokenFile, "")
+	t.Setenv(auth.EnvAuthMode, "")
+	ctx = appContext{
+		Client: api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, UserAgent: "test/1", Retry: api.RetryPolicy{MaxAttempts: 1}}),
+		Mode:   output.ModeTable,
+	}
+	t.Cleanup(func() { ctx = appContext{} })
+
+	origArgs, origStdout := os.Args, os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Args = append([]string{"codemint-api"}, args...)
+	os.Stdout = w
+	runErr := newAPICmd().Execute()
+	w.Close()
+	os.Args, os.Stdout = origArgs, origStdout
+	out, _ := io.ReadAll(r)
+	return string(out), calls, runErr
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestAPIFieldsDefaultToPOSTWithJSONBody(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "note.txt")
+	if err := os.WriteFile(file, []byte("from file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ok := func(w http.ResponseWriter, _ *http.Request) { writeJSON(w, map[string]any{"ok": true}) }
+	_, calls, err := runAPI(t, ok, "/api/things", "-f", "name=42", "-F", "count=42", "-F", "draft=true", "-F", "parent=null", "-F", "note=@"+file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0].method != http.MethodPost || calls[0].auth != "Bearer test-token" {
+		t.Fatalf("calls = %+v", calls)
+	}
+	want := map[string]any{"name": "42", "count": float64(42), "draft": true, "parent": nil, "note": "from file"}
+	for k, v := range want {
+		if got, present := calls[0].body[k]; !present || got != v {
+			t.Fatalf("body[%s] = %#v, want %#v (body %v)", k, got, v, calls[0].body)
+		}
+	}
+}
+
+func TestAPIFieldsGoInQueryForGET(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) { writeJSON(w, map[string]any{"ok": true}) }
+	_, calls, err := runAPI(t, ok, "/api/items/search", "-X", "get", "-f", "q=go", "-F", "limit=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0].method != http.MethodGet || calls[0].query != "limit=5&q=go" || calls[0].body != nil {
+		t.Fatalf("calls = %+v", calls)
+	}
+
+	_, calls, err = runAPI(t, ok, "/api/auth/me")
+	if err != nil || len(calls) != 1 || calls[0].method != http.MethodGet {
+		t.Fatalf("no fields should default to GET: %+v, %v", calls, err)
+	}
+}
+
+func TestAPIPaginateFollowsNextLink(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set("Link", `<http://`+r.Host+`/api/things?cursor=b>; rel="next"`)
+			writeJSON(w, map[string]any{"data": []string{"a1", "a2"}})
+		case "b":
+			writeJSON(w, map[string]any{"data": []string{"b1"}, "next": "/api/things?cursor=c"})
+		default:
+			writeJSON(w, map[string]any{"data": []string{"c1"}})
+		}
+	}
+	out, calls, err := runAPI(t, handler, "/api/things", "--paginate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || calls[1].query != "cursor=b" || calls[2].query != "cursor=c" {
+		t.Fatalf("calls = %+v", calls)
+	}
+	var merged struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &merged); err != nil || strings.Join(merged.Data, ",") != "a1,a2,b1,c1" {
+		t.Fatalf("merged output %q: %v", out, err)
+	}
+}
+
+func TestAPIIncludePrintsStatusAndHeaders(t *testing.T) {
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		writeJSON(w, map[string]any{"ok": true})
+	}
+	out, _, err := runAPI(t, handler, "/api/auth/me", "--include")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "HTTP/1.1 200 OK\n") || !strings.Contains(out, "X-Request-Id: req-123\n") || !strings.HasSuffix(out, "{\"ok\":true}\n") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestAPINon2xxSetsExitCode(t *testing.T) {
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":{"code":"validation_error","message":"name is required"}}`))
+	}
+	_, _, err := runAPI(t, handler, "/api/things", "-X", "POST")
+	if err == nil {
+		t.Fatal("expected an error for a 422 response")
+	}
+	if code := api.ExitCode(err); code != 12 {
+		t.Fatalf("exit code = %d (%v), want 12", code, err)
+	}
+}
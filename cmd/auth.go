@@ -4,6 +4,8 @@ import "github.com/spf13/cobra"
 
 func newAuthCmd() *cobra.Command {
 	authCmd := &cobra.Command{Use: "auth", Short: "Authentication commands"}
-	authCmd.AddCommand(newAuthLoginCmd(), newAuthWhoamiCmd(), newAuthLogoutCmd())
+	profiles := newProfileListCmd()
+	profiles.Use = "profiles"
+	authCmd.AddCommand(newAuthLoginCmd(), newAuthWhoamiCmd(), newAuthLogoutCmd(), newAuthMigrateStoreCmd(), newAuthTokensCmd(), newAuthTokenCmd(), profiles)
 	return authCmd
 }
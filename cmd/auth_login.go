@@ -1,27 +1,80 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/codemint/codemint-cli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
 func newAuthLoginCmd() *cobra.Command {
-	return &cobra.Command{
+	var device, withToken, oidc, noBrowser bool
+	var callbackHost string
+	var port int
+	var timeout time.Duration
+	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Log in using browser flow",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			res, err := auth.Login(cmd.Context(), auth.LoginOptions{
+			opts := auth.LoginOptions{
 				BaseURL: ctx.Config.BaseURL,
 				Client:  ctx.Client,
 				Store:   ctx.Store,
-			})
+
+				NoBrowser:    noBrowser,
+				CallbackHost: callbackHost,
+				CallbackPort: port,
+				Timeout:      timeout,
+			}
+			if port < 0 || port > 65535 {
+				return fmt.Errorf("--port must be between 0 and 65535")
+			}
+			if countTrue(device, withToken, oidc) > 1 {
+				return fmt.Errorf("--device, --with-token and --oidc cannot be combined")
+			}
+			login := auth.Login
+			switch {
+			case device:
+				login = auth.DeviceLogin
+			case withToken:
+				login = func(reqCtx context.Context, opts auth.LoginOptions) (*auth.LoginResult, error) {
+					return auth.LoginWithToken(reqCtx, opts, os.Stdin)
+				}
+			case oidc:
+				login = auth.OIDCLogin
+			}
+			res, err := login(cmd.Context(), opts)
 			if err != nil {
 				return err
 			}
+			if oidc {
+				fmt.Printf("Authenticated as %s with the CI OIDC token; the CodeMint token is kept in memory for this run only.\n", res.Email)
+				fmt.Printf("Set %s=oidc so other commands in this job exchange the OIDC token the same way.\n", auth.EnvAuthMode)
+				return nil
+			}
 			fmt.Printf("Logged in as %s\n", res.Email)
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&device, "device", false, "sign in with a one-time code on another device (for SSH, containers and headless machines)")
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "print the login URL instead of opening a browser")
+	cmd.Flags().IntVar(&port, "port", 0, "fixed port for the login callback, e.g. to forward over SSH (default: random)")
+	cmd.Flags().StringVar(&callbackHost, "callback-host", auth.DefaultCallbackHost, "address the login callback listens on; use 0.0.0.0 inside a container with a published port")
+	cmd.Flags().DurationVar(&timeout, "timeout", auth.DefaultLoginTimeout, "how long to wait for the browser")
+	cmd.Flags().BoolVar(&withToken, "with-token", false, "read a token from standard input instead of opening a browser")
+	cmd.Flags().BoolVar(&oidc, "oidc", false, "exchange the CI provider's OIDC ID token (GitHub Actions, GitLab CI) for a short-lived token kept in memory")
+	return cmd
+}
+
+func countTrue(flags ...bool) int {
+	n := 0
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	return n
 }
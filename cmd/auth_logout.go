@@ -1,21 +1,42 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/codemint/codemint-cli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
 func newAuthLogoutCmd() *cobra.Command {
-	return &cobra.Command{
+	var revoke bool
+	cmd := &cobra.Command{
 		Use:   "logout",
 		Short: "Delete locally stored token",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if revoke {
+				cred, err := auth.LoadCredential(cmd.Context(), ctx.Store)
+				switch {
+				case errors.Is(err, auth.ErrNotLoggedIn):
+				case err != nil:
+					return err
+				default:
+					if err := auth.RevokeCredential(cmd.Context(), ctx.Client, cred); err != nil {
+						return fmt.Errorf("revoke token on server (local token kept): %w", err)
+					}
+				}
+			}
 			if err := ctx.Store.Delete(cmd.Context()); err != nil {
 				return err
 			}
+			if revoke {
+				fmt.Println("Logged out and revoked token")
+				return nil
+			}
 			fmt.Println("Logged out")
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "also invalidate the token and refresh token on the server")
+	return cmd
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/codemint/codemint-cli/internal/auth"
+	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newAuthMigrateStoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-store",
+		Short: "Move plaintext token files into the encrypted token store",
+		RunE: func(c *cobra.Command, _ []string) error {
+			profiles, err := auth.PlaintextProfiles()
+			if err != nil {
+				return err
+			}
+			migrated := 0
+			for _, profile := range profiles {
+				ok, err := auth.MigrateToEncrypted(c.Context(), auth.StoreOptions{
+					Profile:    profile,
+					KeyFile:    ctx.Config.TokenStore.KeyFile,
+					Passphrase: promptPassphrase,
+				})
+				if err != nil {
+					return fmt.Errorf("migrate profile %s: %w", profile, err)
+				}
+				if ok {
+					migrated++
+					fmt.Printf("Encrypted token for profile %s\n", profile)
+				}
+			}
+			path, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if file.TokenStore.Backend != auth.BackendEncrypted {
+				if err := config.SetValue(path, "token_store.backend", auth.BackendEncrypted, false); err != nil {
+					return err
+				}
+				fmt.Printf("Set token_store.backend to %q in %s\n", auth.BackendEncrypted, path)
+			}
+			if migrated == 0 {
+				fmt.Println("No plaintext tokens found")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newAuthTokenCmd() *cobra.Command {
+	var show bool
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Print the active token for use in scripts",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if stdoutIsTerminal() && !show {
+				return fmt.Errorf("refusing to print the token to a terminal; pipe the output or pass --show")
+			}
+			tok, source, err := activeToken(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if tok == "" {
+				return fmt.Errorf("not logged in: run `codemint auth login`")
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(map[string]string{"token": tok, "source": source})
+			}
+			fmt.Println(tok)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&show, "show", false, "print the token even when stdout is a terminal")
+	return cmd
+}
+
+func stdoutIsTerminal() bool {
+	st, err := os.Stdout.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newAuthTokensCmd() *cobra.Command {
+	tokens := &cobra.Command{Use: "tokens", Short: "List and revoke CLI tokens issued to your account"}
+	tokens.AddCommand(newAuthTokensListCmd(), newAuthTokensRevokeCmd())
+	return tokens
+}
+
+func newAuthTokensListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List CLI tokens",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			tok, err := tokenFromStore(cmd.Context())
+			if err != nil {
+				return err
+			}
+			list, err := ctx.Client.ListCLITokens(cmd.Context(), tok)
+			if err != nil {
+				return err
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(list)
+			}
+			rows := make([][]string, 0, len(list.Tokens))
+			for _, t := range list.Tokens {
+				current := ""
+				if t.Current {
+					current = "*"
+				}
+				rows = append(rows, []string{current, t.ID, t.Name, tokenTime(t.CreatedAt), tokenTime(t.LastUsedAt), tokenTime(t.ExpiresAt)})
+			}
+			return output.PrintTable([]string{"", "ID", "Name", "Created", "Last used", "Expires"}, rows)
+		},
+	}
+}
+
+func newAuthTokensRevokeCmd() *cobra.Command {
+	var allOthers bool
+	cmd := &cobra.Command{
+		Use:   "revoke <id> | --all-others",
+		Short: "Revoke a CLI token, or every token except the current one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if allOthers == (len(args) == 1) || len(args) > 1 {
+				return fmt.Errorf("auth tokens revoke expects exactly one token id or --all-others")
+			}
+			tok, err := tokenFromStore(cmd.Context())
+			if err != nil {
+				return err
+			}
+			list, err := ctx.Client.ListCLITokens(cmd.Context(), tok)
+			if err != nil {
+				return err
+			}
+			if !allOthers {
+				// Revoking the token in use would leave a dead credential in
+				// the store; logout also revokes its refresh token.
+				for _, t := range list.Tokens {
+					if t.Current && t.ID == args[0] {
+						return fmt.Errorf("token %s is the one this CLI is using; run `codemint auth logout --revoke` instead", args[0])
+					}
+				}
+				if err := ctx.Client.RevokeCLIToken(cmd.Context(), tok, args[0]); err != nil {
+					return err
+				}
+				fmt.Printf("Revoked token %s\n", args[0])
+				return nil
+			}
+			revoked := 0
+			for _, t := range list.Tokens {
+				if t.Current {
+					continue
+				}
+				if err := ctx.Client.RevokeCLIToken(cmd.Context(), tok, t.ID); err != nil {
+					return fmt.Errorf("revoke token %s after revoking %d: %w", t.ID, revoked, err)
+				}
+				revoked++
+			}
+			fmt.Printf("Revoked %d other token(s)\n", revoked)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&allOthers, "all-others", false, "revoke every token except the one in use")
+	return cmd
+}
+
+// tokenTime shortens an RFC 3339 timestamp for table output.
+func tokenTime(v string) string {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return v
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/auth"
+	"github.com/codemint/codemint-cli/internal/output"
+)
+
+func TestRevokeRefusesTheCurrentToken(t *testing.T) {
+	var revoked []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			revoked = append(revoked, strings.TrimPrefix(r.URL.Path, "/api/auth/cli-token/"))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, api.CLITokenListResponse{Tokens: []api.CLIToken{{ID: "tok_me", Current: true}, {ID: "tok_old"}}})
+	}))
+	defer ts.Close()
+	t.Setenv(auth.EnvToken, "test-token")
+	t.Setenv(auth.EnvTokenFile, "")
+	t.Setenv(auth.EnvAuthMode, "")
+	ctx = appContext{
+		Client: api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, Retry: api.RetryPolicy{MaxAttempts: 1}}),
+		Mode:   output.ModeTable,
+	}
+	t.Cleanup(func() { ctx = appContext{} })
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	os.Args = []string{"codemint-revoke", "tok_me"}
+	if err := newAuthTokensRevokeCmd().Execute(); err == nil || !strings.Contains(err.Error(), "auth logout --revoke") {
+		t.Fatalf("revoking the current token: %v", err)
+	}
+	os.Args = []string{"codemint-revoke", "tok_old"}
+	if err := newAuthTokensRevokeCmd().Execute(); err != nil {
+		t.Fatalf("revoking another token: %v", err)
+	}
+	if strings.Join(revoked, ",") != "tok_old" {
+		t.Fatalf("server saw revocations %v", revoked)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/codemint/codemint-cli/internal/api"
 	"github.com/codemint/codemint-cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -10,7 +11,7 @@ func newAuthWhoamiCmd() *cobra.Command {
 		Use:   "whoami",
 		Short: "Show current authenticated user",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			tok, err := tokenFromStore()
+			tok, source, err := activeToken(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -19,9 +20,15 @@ func newAuthWhoamiCmd() *cobra.Command {
 				return err
 			}
 			if ctx.Mode == output.ModeJSON {
-				return output.PrintJSON(me)
+				return output.PrintJSON(struct {
+					*api.AuthMeResponse
+					Profile string `json:"profile"`
+					BaseURL string `json:"baseUrl"`
+					Org     string `json:"org,omitempty"`
+					Source  string `json:"credentialSource"`
+				}{me, ctx.Config.Profile, ctx.Config.BaseURL, ctx.Config.Org, source})
 			}
-			return output.PrintTable([]string{"ID", "Email", "Name"}, [][]string{{me.ID, me.Email, me.Name}})
+			return output.PrintTable([]string{"ID", "Email", "Name", "Profile", "Endpoint", "Org", "Source"}, [][]string{{me.ID, me.Email, me.Name, ctx.Config.Profile, ctx.Config.BaseURL, orgLabel(ctx.Config.Org), source}})
 		},
 	}
 }
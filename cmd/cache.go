@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the local API response cache",
+	}
+	cacheCmd.AddCommand(newCacheInfoCmd(), newCacheClearCmd())
+	return cacheCmd
+}
+
+func newCacheInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show cache location and size",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if ctx.Cache == nil {
+				return fmt.Errorf("cache directory unavailable on this system")
+			}
+			info, err := ctx.Cache.Info()
+			if err != nil {
+				return err
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(map[string]any{
+					"dir":      info.Dir,
+					"entries":  info.Entries,
+					"bytes":    info.Bytes,
+					"oldest":   info.Oldest,
+					"newest":   info.Newest,
+					"ttl":      ctx.Config.Cache.EffectiveTTL().String(),
+					"disabled": ctx.Config.Cache.Disabled,
+				})
+			}
+			rows := [][]string{
+				{"Directory", info.Dir},
+				{"Entries", strconv.Itoa(info.Entries)},
+				{"Size", fmt.Sprintf("%d bytes", info.Bytes)},
+				{"TTL", ctx.Config.Cache.EffectiveTTL().String()},
+				{"Enabled", strconv.FormatBool(!ctx.Config.Cache.Disabled)},
+			}
+			if !info.Newest.IsZero() {
+				rows = append(rows, []string{"Last write", info.Newest.Format(time.RFC3339)})
+			}
+			return output.PrintTable([]string{"Setting", "Value"}, rows)
+		},
+	}
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Delete all cached responses and offline catalog data",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if ctx.Cache == nil {
+				return fmt.Errorf("cache directory unavailable on this system")
+			}
+			n, err := ctx.Cache.Clear()
+			if err != nil {
+				return err
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(map[string]any{"cleared": n})
+			}
+			fmt.Printf("Cleared %d cache entries\n", n)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change settings",
+	}
+	configCmd.AddCommand(newConfigGetCmd(), newConfigSetCmd(), newConfigUnsetCmd(), newConfigListCmd())
+	return configCmd
+}
+
+type settingView struct {
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value"`
+	Origin string          `json:"origin"`
+	Source string          `json:"source,omitempty"`
+}
+
+func viewSetting(s config.Setting) settingView {
+	return settingView{Key: s.Key, Value: s.Value, Origin: s.Origin.Layer, Source: s.Origin.Source}
+}
+
+// configTarget is the file `config set` and `config unset` edit.
+func configTarget(repo bool) (string, error) {
+	if !repo {
+		return config.Path(cfgPath)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return config.RepoPath(wd), nil
+}
+
+func newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a setting's effective value",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("config get expects exactly one key")
+			}
+			r, err := config.Resolve(loadOptions())
+			if err != nil {
+				return err
+			}
+			s, ok, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(viewSetting(s))
+			}
+			fmt.Println(s.Display())
+			return nil
+		},
+	}
+}
+
+func newConfigSetCmd() *cobra.Command {
+	var repo bool
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Save a setting in the user config (or the repository's with --repo)",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("config set expects a key and a value")
+			}
+			path, err := configTarget(repo)
+			if err != nil {
+				return err
+			}
+			if err := config.SetValue(path, args[0], args[1], repo); err != nil {
+				return err
+			}
+			fmt.Printf("Set %s in %s\n", args[0], path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&repo, "repo", false, "write to this repository's .codemint/config.json")
+	return cmd
+}
+
+func newConfigUnsetCmd() *cobra.Command {
+	var repo bool
+	cmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting from the user config (or the repository's with --repo)",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("config unset expects exactly one key")
+			}
+			path, err := configTarget(repo)
+			if err != nil {
+				return err
+			}
+			removed, err := config.UnsetValue(path, args[0], repo)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				fmt.Printf("%s is not set in %s\n", args[0], path)
+				return nil
+			}
+			fmt.Printf("Unset %s in %s\n", args[0], path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&repo, "repo", false, "edit this repository's .codemint/config.json")
+	return cmd
+}
+
+func newConfigListCmd() *cobra.Command {
+	var showOrigin, all bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List effective settings",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			r, err := config.Resolve(loadOptions())
+			if err != nil {
+				return err
+			}
+			settings := r.Settings()
+			if all {
+				settings = make([]config.Setting, 0, len(config.Keys()))
+				for _, key := range config.Keys() {
+					s, _, _ := r.Get(key)
+					settings = append(settings, s)
+				}
+			}
+			if ctx.Mode == output.ModeJSON {
+				views := make([]settingView, 0, len(settings))
+				for _, s := range settings {
+					views = append(views, viewSetting(s))
+				}
+				return output.PrintJSON(views)
+			}
+			headers := []string{"Key", "Value"}
+			if showOrigin {
+				headers = append(headers, "Origin")
+			}
+			rows := make([][]string, 0, len(settings))
+			for _, s := range settings {
+				row := []string{s.Key, s.Display()}
+				if showOrigin {
+					row = append(row, s.Origin.String())
+				}
+				rows = append(rows, row)
+			}
+			return output.PrintTable(headers, rows)
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "include keys that are not set")
+	cmd.Flags().BoolVar(&showOrigin, "show-origin", false, "show which layer (default, user, profile, repo, env, flag) set each value")
+	return cmd
+}
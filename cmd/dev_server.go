@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/codemint/codemint-cli/internal/devserver"
+	"github.com/spf13/cobra"
+)
+
+func newDevServerCmd() *cobra.Command {
+	var fixtures string
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "dev-server",
+		Short: "Run an in-memory CodeMint API for local testing",
+		RunE: func(c *cobra.Command, _ []string) error {
+			f := devserver.Fixtures{}
+			if fixtures != "" {
+				var err error
+				if f, err = devserver.LoadFixtures(fixtures); err != nil {
+					return err
+				}
+			}
+			srv := devserver.New(f)
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			base := "http://" + ln.Addr().String()
+			fmt.Printf("CodeMint dev server listening on %s\n", base)
+			fmt.Printf("Sign in with: codemint --base-url %s auth login\n", base)
+
+			hs := &http.Server{Handler: srv}
+			go func() {
+				<-c.Context().Done()
+				_ = hs.Shutdown(context.Background())
+			}()
+			if err := hs.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fixtures, "fixtures", "", "directory with user.json, items.json, orgs.json and token.txt")
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8787", "listen address")
+	return cmd
+}
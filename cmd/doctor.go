@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/auth"
 	"github.com/codemint/codemint-cli/internal/manifest"
 	"github.com/codemint/codemint-cli/internal/output"
 	"github.com/spf13/cobra"
@@ -22,12 +28,8 @@ func newDoctorCmd() *cobra.Command {
 		RunE: func(c *cobra.Command, _ []string) error {
 			checks := make([]doctorCheck, 0, 4)
 
-			tok, err := ctx.Store.Get(c.Context())
-			if err != nil || tok == "" {
-				checks = append(checks, doctorCheck{Name: "auth token", OK: false, Detail: "missing token; run codemint auth login"})
-			} else {
-				checks = append(checks, doctorCheck{Name: "auth token", OK: true, Detail: "token available in secure store"})
-			}
+			checks = append(checks, tokenCheck(c.Context()))
+			checks = append(checks, doctorCheck{Name: "org", OK: true, Detail: orgLabel(ctx.Config.Org)})
 
 			wd, err := os.Getwd()
 			if err != nil {
@@ -56,6 +58,10 @@ func newDoctorCmd() *cobra.Command {
 				}
 				checks = append(checks, doctorCheck{Name: "path", OK: st.IsDir(), Detail: dir})
 			}
+			checks = append(checks, tlsChecks(c.Context())...)
+			if !ctx.Config.Offline {
+				checks = append(checks, serverCheck(c.Context()))
+			}
 			if ctx.Mode == output.ModeJSON {
 				return output.PrintJSON(checks)
 			}
@@ -72,3 +78,79 @@ func newDoctorCmd() *cobra.Command {
 	}
 	return cmd
 }
+
+func tlsChecks(reqCtx context.Context) []doctorCheck {
+	t := ctx.Config.TLS
+	settings := []string{"min=" + firstNonEmpty(t.MinVersion, "1.2")}
+	if t.CAFile != "" {
+		settings = append(settings, "ca="+t.CAFile)
+	}
+	if t.CertFile != "" {
+		settings = append(settings, "client-cert="+t.CertFile)
+	}
+	if len(t.Pins) > 0 {
+		settings = append(settings, fmt.Sprintf("pins=%d", len(t.Pins)))
+	}
+	if t.InsecureSkipVerify {
+		settings = append(settings, "insecure-skip-verify")
+	}
+	checks := []doctorCheck{{Name: "tls config", OK: !t.InsecureSkipVerify, Detail: strings.Join(settings, " ")}}
+	if ctx.Config.Offline {
+		return checks
+	}
+	state, err := ctx.Client.Handshake(reqCtx)
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{Name: "tls handshake", OK: false, Detail: err.Error()})
+	case state == nil:
+		checks = append(checks, doctorCheck{Name: "tls handshake", OK: false, Detail: "base URL is not https; traffic is unencrypted"})
+	default:
+		detail := tls.VersionName(state.Version) + " " + tls.CipherSuiteName(state.CipherSuite)
+		if len(state.PeerCertificates) > 0 {
+			leaf := state.PeerCertificates[0]
+			detail += fmt.Sprintf(", server cert %q issued by %q, key sha256/%s", leaf.Subject.CommonName, leaf.Issuer.CommonName, api.SPKIHash(leaf))
+		}
+		checks = append(checks, doctorCheck{Name: "tls handshake", OK: true, Detail: detail})
+	}
+	return checks
+}
+
+func serverCheck(reqCtx context.Context) doctorCheck {
+	caps, err := ctx.Client.Capabilities(reqCtx)
+	switch {
+	case err != nil:
+		return doctorCheck{Name: "server api", OK: false, Detail: err.Error()}
+	case caps.Legacy:
+		return doctorCheck{Name: "server api", OK: true, Detail: fmt.Sprintf("legacy server without /api/meta; CLI speaks API v%d", api.APIVersion)}
+	}
+	detail := fmt.Sprintf("API v%d (supports v%d-v%d)", caps.APIVersion, caps.MinAPIVersion, caps.MaxAPIVersion)
+	if len(caps.Features) > 0 {
+		detail += "; features: " + strings.Join(caps.Features, ", ")
+	}
+	return doctorCheck{Name: "server api", OK: true, Detail: detail}
+}
+
+// tokenCheck reports the token this run would use and where it came from,
+// as `auth whoami` does, without calling the server.
+func tokenCheck(reqCtx context.Context) doctorCheck {
+	cred, source, err := auth.ResolveCredential(reqCtx, ctx.Store, ctx.Client)
+	if errors.Is(err, auth.ErrNotLoggedIn) {
+		return doctorCheck{Name: "auth token", OK: false, Detail: "missing token; run codemint auth login"}
+	}
+	if err != nil {
+		return doctorCheck{Name: "auth token", OK: false, Detail: fmt.Sprintf("%s (source: %s)", err, source)}
+	}
+	detail := "token available (source: " + source + ")"
+	warning, err := auth.CheckExpiry(cred)
+	switch {
+	case err != nil && cred.RefreshToken != "":
+		return doctorCheck{Name: "auth token", OK: true, Detail: detail + "; expired, will be renewed with the stored refresh token"}
+	case err != nil:
+		return doctorCheck{Name: "auth token", OK: false, Detail: fmt.Sprintf("%s (source: %s)", err, source)}
+	case warning != "" && cred.RefreshToken == "":
+		return doctorCheck{Name: "auth token", OK: true, Detail: detail + "; " + warning}
+	case cred.ExpiresAt != nil:
+		return doctorCheck{Name: "auth token", OK: true, Detail: detail + "; expires " + cred.ExpiresAt.Local().Format("2006-01-02")}
+	}
+	return doctorCheck{Name: "auth token", OK: true, Detail: detail}
+}
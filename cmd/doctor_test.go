@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/auth"
+)
+
+type staticStore struct{ raw string }
+
+func (s staticStore) Set(context.Context, string) error { return nil }
+func (s staticStore) Get(context.Context) (string, error) {
+	if s.raw == "" {
+		return "", auth.ErrNotLoggedIn
+	}
+	return s.raw, nil
+}
+func (s staticStore) Delete(context.Context) error { return nil }
+
+func storeWith(t *testing.T, cred auth.Credential) staticStore {
+	t.Helper()
+	b, err := json.Marshal(cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return staticStore{raw: string(b)}
+}
+
+func TestDoctorTokenCheck(t *testing.T) {
+	t.Setenv(auth.EnvToken, "")
+	t.Setenv(auth.EnvTokenFile, "")
+	t.Setenv(auth.EnvAuthMode, "")
+	t.Cleanup(func() { ctx = appContext{} })
+	past := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name   string
+		store  staticStore
+		ok     bool
+		detail string
+	}{
+		{"missing", staticStore{}, false, "missing token"},
+		{"stored", storeWith(t, auth.Credential{Token: "t"}), true, "source: store"},
+		{"expired", storeWith(t, auth.Credential{Token: "t", ExpiresAt: &past}), false, "expired on"},
+		{"expired with refresh token", storeWith(t, auth.Credential{Token: "t", RefreshToken: "r", ExpiresAt: &past}), true, "will be renewed"},
+	}
+	for _, tc := range cases {
+		ctx = appContext{Store: tc.store}
+		got := tokenCheck(context.Background())
+		if got.OK != tc.ok || !strings.Contains(got.Detail, tc.detail) {
+			t.Errorf("%s: %+v", tc.name, got)
+		}
+	}
+
+	t.Setenv(auth.EnvToken, "env-token")
+	ctx = appContext{Store: staticStore{}}
+	if got := tokenCheck(context.Background()); !got.OK || !strings.Contains(got.Detail, "source: env") {
+		t.Errorf("env token: %+v", got)
+	}
+}
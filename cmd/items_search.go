@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -16,22 +17,30 @@ func newItemsSearchCmd() *cobra.Command {
 	var tags []string
 	var page int
 	var limit int
+	var all bool
+	var maxResults int
 
 	cmd := &cobra.Command{
 		Use:   "search",
 		Short: "Search items",
 		RunE: func(c *cobra.Command, _ []string) error {
-			tok, err := tokenFromStore()
+			tok, err := tokenFromStore(c.Context())
 			if err != nil {
 				return err
 			}
-			resp, err := ctx.Client.ItemsSearch(c.Context(), tok, api.ItemsSearchRequest{
+			req := api.ItemsSearchRequest{
 				Q:     q,
 				Type:  itemType,
 				Tags:  tags,
 				Page:  page,
 				Limit: limit,
-			})
+			}
+			var resp *api.ItemsSearchResponse
+			if all || maxResults > 0 {
+				resp, err = searchAll(c.Context(), tok, req, maxResults)
+			} else {
+				resp, err = ctx.Client.ItemsSearch(c.Context(), tok, req)
+			}
 			if err != nil {
 				return err
 			}
@@ -60,6 +69,22 @@ func newItemsSearchCmd() *cobra.Command {
 	cmd.Flags().StringSliceVar(&tags, "tags", nil, "comma-separated tags")
 	cmd.Flags().IntVar(&page, "page", 1, "page number")
 	cmd.Flags().IntVar(&limit, "limit", 20, "page size")
+	cmd.Flags().BoolVar(&all, "all", false, "fetch every page of results")
+	cmd.Flags().IntVar(&maxResults, "max-results", 0, "stop after this many results (implies --all)")
 	_ = cmd.MarkFlagRequired("q")
 	return cmd
 }
+
+func searchAll(reqCtx context.Context, tok string, req api.ItemsSearchRequest, maxResults int) (*api.ItemsSearchResponse, error) {
+	it := ctx.Client.SearchAll(tok, req, maxResults)
+	out := &api.ItemsSearchResponse{Data: []api.Item{}, Page: 1}
+	for it.Next(reqCtx) {
+		out.Data = append(out.Data, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	out.Limit = len(out.Data)
+	out.Total = it.Total()
+	return out, nil
+}
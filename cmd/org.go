@@ -4,6 +4,6 @@ import "github.com/spf13/cobra"
 
 func newOrgCmd() *cobra.Command {
 	org := &cobra.Command{Use: "org", Short: "Organization commands"}
-	org.AddCommand(newOrgListCmd())
+	org.AddCommand(newOrgListCmd(), newOrgUseCmd())
 	return org
 }
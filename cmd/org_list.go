@@ -10,7 +10,7 @@ func newOrgListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List organizations for current user",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			tok, err := tokenFromStore()
+			tok, err := tokenFromStore(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newOrgUseCmd() *cobra.Command {
+	var repo, clear bool
+	cmd := &cobra.Command{
+		Use:   "use <slug>",
+		Short: "Set the organization used for catalog requests",
+		RunE: func(c *cobra.Command, args []string) error {
+			if clear == (len(args) == 1) || len(args) > 1 {
+				return fmt.Errorf("org use expects exactly one org slug or --clear")
+			}
+			slug := ""
+			if !clear {
+				slug = args[0]
+				if err := checkMembership(c, slug); err != nil {
+					return err
+				}
+			}
+			scope := "profile " + ctx.Config.Profile
+			if repo {
+				if err := saveRepoOrg(slug); err != nil {
+					return err
+				}
+				scope = "this repository"
+			} else if err := saveProfileOrg(ctx.Config.Profile, slug); err != nil {
+				return err
+			}
+			if clear {
+				fmt.Printf("Cleared the organization for %s\n", scope)
+				return nil
+			}
+			fmt.Printf("Using organization %s for %s\n", slug, scope)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&repo, "repo", false, "pin the org in this repository's .codemint/config.json instead of the profile")
+	cmd.Flags().BoolVar(&clear, "clear", false, "go back to your default scope")
+	return cmd
+}
+
+// checkMembership makes sure the user belongs to slug before it is saved.
+func checkMembership(c *cobra.Command, slug string) error {
+	if ctx.Config.Offline {
+		return nil
+	}
+	tok, err := tokenFromStore(c.Context())
+	if err != nil {
+		return err
+	}
+	orgs, err := ctx.Client.OrgList(c.Context(), tok)
+	if err != nil {
+		return err
+	}
+	for _, o := range orgs.Organizations {
+		if o.Slug == slug {
+			return nil
+		}
+	}
+	return fmt.Errorf("you are not a member of organization %q; see `codemint org list`", slug)
+}
+
+func saveProfileOrg(profile, slug string) error {
+	path, file, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[profile]; !ok && profile == "default" {
+		if slug == "" {
+			_, err := config.UnsetValue(path, "org", false)
+			return err
+		}
+		return config.SetValue(path, "org", slug, false)
+	}
+	if slug == "" {
+		_, err := config.UnsetProfileValue(path, profile, "org")
+		return err
+	}
+	return config.SetProfileValues(path, profile, map[string]string{"org": slug})
+}
+
+func saveRepoOrg(slug string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	path := config.RepoPath(wd)
+	if slug == "" {
+		_, err := config.UnsetValue(path, "org", true)
+		return err
+	}
+	return config.SetValue(path, "org", slug, true)
+}
+
+// orgLabel names the scope catalog requests run in.
+func orgLabel(org string) string {
+	if org == "" {
+		return "(personal)"
+	}
+	return org
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/codemint/codemint-cli/internal/auth"
+)
+
+var (
+	passphraseOnce sync.Once
+	passphrase     string
+	passphraseErr  error
+)
+
+// promptPassphrase asks for the encrypted token store passphrase once per
+// run. It refuses when stdin is not a terminal so scripts fail fast.
+func promptPassphrase() (string, error) {
+	passphraseOnce.Do(func() {
+		if !stdinIsTerminal() {
+			passphraseErr = auth.ErrNoTokenKey
+			return
+		}
+		fmt.Fprint(os.Stderr, "Token store passphrase: ")
+		restore := disableEcho()
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		restore()
+		fmt.Fprintln(os.Stderr)
+		if err != nil && line == "" {
+			passphraseErr = fmt.Errorf("read passphrase: %w", err)
+			return
+		}
+		passphrase = strings.TrimRight(line, "\r\n")
+	})
+	return passphrase, passphraseErr
+}
+
+func stdinIsTerminal() bool {
+	st, err := os.Stdin.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
+
+func disableEcho() func() {
+	if runtime.GOOS == "windows" {
+		return func() {}
+	}
+	stty := func(arg string) error {
+		c := exec.Command("stty", arg)
+		c.Stdin = os.Stdin
+		return c.Run()
+	}
+	if stty("-echo") != nil {
+		return func() {}
+	}
+	return func() { _ = stty("echo") }
+}
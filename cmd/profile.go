@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/codemint/codemint-cli/internal/auth"
+	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/codemint/codemint-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newProfileCmd() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named endpoints and their credentials",
+	}
+	profileCmd.AddCommand(newProfileListCmd(), newProfileAddCmd(), newProfileUseCmd(), newProfileRemoveCmd(), newProfileShowCmd())
+	return profileCmd
+}
+
+type profileView struct {
+	Name    string           `json:"name"`
+	Active  bool             `json:"active"`
+	BaseURL string           `json:"base_url"`
+	Proxy   string           `json:"proxy,omitempty"`
+	Org     string           `json:"org,omitempty"`
+	TLS     config.TLSConfig `json:"tls"`
+	Email   string           `json:"email,omitempty"`
+}
+
+// loadUserConfig reads the config file that profile commands edit.
+func loadUserConfig() (string, config.Config, error) {
+	path, err := config.Path(cfgPath)
+	if err != nil {
+		return "", config.Config{}, err
+	}
+	cfg, err := config.LoadFile(path)
+	return path, cfg, err
+}
+
+// profileConfig resolves the settings of the named profile rather than the
+// active one, so its token store sees that profile's base URL and credential
+// helper.
+func profileConfig(name string) (config.Config, error) {
+	opts := loadOptions()
+	opts.ProfileOverride = name
+	opts.BaseURLOverride = ""
+	return config.Load(opts)
+}
+
+// checkProfileName rejects names that config.ValidProfileName does not
+// accept.
+func checkProfileName(name string) error {
+	if !config.ValidProfileName(name) {
+		return fmt.Errorf("invalid profile name %q: use only letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// profileNames lists the configured profiles plus the built-in default.
+func profileNames(cfg config.Config) []string {
+	names := []string{"default"}
+	for name := range cfg.Profiles {
+		if name != "default" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+func viewProfile(file config.Config, name string) profileView {
+	v := profileView{Name: name, Active: name == ctx.Config.Profile, BaseURL: file.BaseURL, Proxy: file.Proxy, Org: file.Org, TLS: file.TLS}
+	if p, ok := file.Profiles[name]; ok {
+		if p.BaseURL != "" {
+			v.BaseURL = p.BaseURL
+		}
+		if p.Proxy != "" {
+			v.Proxy = p.Proxy
+		}
+		if p.Org != "" {
+			v.Org = p.Org
+		}
+		v.TLS = v.TLS.Merge(p.TLS)
+	}
+	return v
+}
+
+func newProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			_, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			views := make([]profileView, 0, len(file.Profiles)+1)
+			for _, name := range profileNames(file) {
+				views = append(views, viewProfile(file, name))
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(views)
+			}
+			rows := make([][]string, 0, len(views))
+			for _, v := range views {
+				active := ""
+				if v.Active {
+					active = "*"
+				}
+				rows = append(rows, []string{active, v.Name, v.BaseURL, v.Org})
+			}
+			return output.PrintTable([]string{"", "Name", "Base URL", "Org"}, rows)
+		},
+	}
+}
+
+func newProfileAddCmd() *cobra.Command {
+	var p config.ProfileConfig
+	var use bool
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a profile with its own endpoint",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("profile add expects exactly one name")
+			}
+			name := args[0]
+			if err := checkProfileName(name); err != nil {
+				return err
+			}
+			if p.BaseURL == "" {
+				return fmt.Errorf("--base-url is required")
+			}
+			if u, err := url.Parse(p.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid base URL %q", p.BaseURL)
+			}
+			path, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[name]; ok {
+				return fmt.Errorf("profile %q already exists; remove it first", name)
+			}
+			values := map[string]string{"base_url": p.BaseURL}
+			for key, v := range map[string]string{
+				"proxy":           p.Proxy,
+				"org":             p.Org,
+				"tls.ca_file":     p.TLS.CAFile,
+				"tls.cert_file":   p.TLS.CertFile,
+				"tls.key_file":    p.TLS.KeyFile,
+				"tls.min_version": p.TLS.MinVersion,
+			} {
+				if v != "" {
+					values[key] = v
+				}
+			}
+			if err := config.SetProfileValues(path, name, values); err != nil {
+				return err
+			}
+			if use {
+				if err := config.SetValue(path, "profile", name, false); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Added profile %s (%s)\n", name, p.BaseURL)
+			if use {
+				fmt.Printf("Switched to profile %s\n", name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&p.BaseURL, "base-url", "", "API base URL for this profile")
+	cmd.Flags().StringVar(&p.Proxy, "proxy", "", "HTTP(S) proxy URL for this profile")
+	cmd.Flags().StringVar(&p.Org, "org", "", "default organization slug")
+	cmd.Flags().StringVar(&p.TLS.CAFile, "ca-file", "", "PEM CA bundle to trust")
+	cmd.Flags().StringVar(&p.TLS.CertFile, "client-cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&p.TLS.KeyFile, "client-key", "", "PEM private key for --client-cert")
+	cmd.Flags().StringVar(&p.TLS.MinVersion, "tls-min-version", "", "minimum TLS version: 1.2 or 1.3")
+	cmd.Flags().BoolVar(&use, "use", false, "make the new profile active")
+	return cmd
+}
+
+func newProfileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a profile active",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("profile use expects exactly one name")
+			}
+			name := args[0]
+			path, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[name]; !ok && name != "default" {
+				return fmt.Errorf("unknown profile %q; see `codemint profile list`", name)
+			}
+			if err := config.SetValue(path, "profile", name, false); err != nil {
+				return err
+			}
+			fmt.Printf("Switched to profile %s (%s)\n", name, viewProfile(file, name).BaseURL)
+			return nil
+		},
+	}
+}
+
+func newProfileRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a profile and its stored credentials",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("profile remove expects exactly one name")
+			}
+			name := args[0]
+			path, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[name]; !ok {
+				return fmt.Errorf("unknown profile %q", name)
+			}
+			// Resolve the store before the profile's settings are removed.
+			profileCfg, err := profileConfig(name)
+			if err != nil {
+				return err
+			}
+			if _, err := config.RemoveProfile(path, name); err != nil {
+				return err
+			}
+			if file.Profile == name {
+				if _, err := config.UnsetValue(path, "profile", false); err != nil {
+					return err
+				}
+			}
+			if store, err := tokenStoreFor(profileCfg, name); err == nil {
+				_ = store.Delete(c.Context())
+			}
+			fmt.Printf("Removed profile %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newProfileShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show a profile's settings (default: the active profile)",
+		RunE: func(c *cobra.Command, args []string) error {
+			name := ctx.Config.Profile
+			if len(args) > 0 {
+				name = args[0]
+			}
+			_, file, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[name]; !ok && name != "default" {
+				return fmt.Errorf("unknown profile %q", name)
+			}
+			v := viewProfile(file, name)
+			if profileCfg, err := profileConfig(name); err == nil {
+				if store, err := tokenStoreFor(profileCfg, name); err == nil {
+					if cred, err := auth.LoadCredential(c.Context(), store); err == nil {
+						v.Email = cred.Email
+					}
+				}
+			}
+			if ctx.Mode == output.ModeJSON {
+				return output.PrintJSON(v)
+			}
+			rows := [][]string{
+				{"name", v.Name},
+				{"active", fmt.Sprint(v.Active)},
+				{"base_url", v.BaseURL},
+				{"proxy", v.Proxy},
+				{"org", v.Org},
+				{"tls.ca_file", v.TLS.CAFile},
+				{"tls.cert_file", v.TLS.CertFile},
+				{"tls.min_version", v.TLS.MinVersion},
+				{"logged_in_as", v.Email},
+			}
+			return output.PrintTable([]string{"Key", "Value"}, rows)
+		},
+	}
+}
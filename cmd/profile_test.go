@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/codemint/codemint-cli/internal/output"
+)
+
+func TestProfileRemoveErasesWithThatProfilesSettings(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "helper.log")
+	helper := filepath.Join(dir, "helper.sh")
+	script := "#!/bin/sh\necho \"$2 $(cat)\" >> \"$1\"\n"
+	if err := os.WriteFile(helper, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.json")
+	body := `{"base_url":"https://codemint.example","credential_helper":"` + helper + ` ` + log + `",
+		"profiles":{"staging":{"base_url":"https://staging.codemint.example"}}}`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"CODEMINT_PROFILE", "CODEMINT_BASE_URL", "CODEMINT_CREDENTIAL_HELPER"} {
+		t.Setenv(name, "")
+	}
+	origPath := cfgPath
+	cfgPath = path
+	t.Cleanup(func() { cfgPath = origPath })
+	active, err := config.Load(config.LoadOptions{ConfigPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = appContext{Config: active, Mode: output.ModeTable}
+	t.Cleanup(func() { ctx = appContext{} })
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	os.Args = []string{"codemint-profile-remove", "staging"}
+	if err := newProfileRemoveCmd().Execute(); err != nil {
+		t.Fatalf("profile remove: %v", err)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("helper was not run: %v", err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "erase ") || !strings.Contains(got, `"base_url":"https://staging.codemint.example"`) {
+		t.Fatalf("helper erased with %q", got)
+	}
+	file, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := file.Profiles["staging"]; ok {
+		t.Fatal("profile still in config")
+	}
+}
@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"os"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/codemint/codemint-cli/internal/api"
 	"github.com/codemint/codemint-cli/internal/auth"
 	"github.com/codemint/codemint-cli/internal/config"
+	"github.com/codemint/codemint-cli/internal/httpcache"
 	"github.com/codemint/codemint-cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -26,14 +26,19 @@ var (
 	flagJSON  bool
 	flagURL   string
 	flagProf  string
+	flagOrg   string
 	flagDebug bool
+	flagOffln bool
+	flagTLS   config.TLSConfig
 )
 
 type appContext struct {
-	Config config.Config
-	Client *api.Client
-	Store  auth.TokenStore
-	Mode   output.Mode
+	Config    config.Config
+	Client    *api.Client
+	Store     auth.TokenStore
+	Refresher *auth.Refresher
+	Cache     *httpcache.Store
+	Mode      output.Mode
 }
 
 var rootCmd = &cobra.Command{
@@ -41,33 +46,89 @@ var rootCmd = &cobra.Command{
 	Short: "CodeMint CLI",
 	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 		mode := output.FromJSONFlag(flagJSON)
-		cfg, err := config.Load(config.LoadOptions{ConfigPath: cfgPath, BaseURLOverride: flagURL, ProfileOverride: flagProf})
+		cfg, err := config.Load(loadOptions())
 		if err != nil {
 			return err
 		}
 
-		store, err := auth.NewTokenStore(cfg.Profile)
+		store, err := tokenStoreFor(cfg, cfg.Profile)
 		if err != nil {
 			return fmt.Errorf("init secure token store: %w", err)
 		}
 
+		var cache *httpcache.Store
+		if dir, err := httpcache.DefaultDir(); err == nil {
+			cache = httpcache.New(dir)
+		}
+		var clientCache *httpcache.Store
+		if !cfg.Cache.Disabled {
+			clientCache = cache
+		}
+
+		transport, err := api.NewTransport(api.TransportOptions{TLS: api.TLSOptions{
+			CAFile:             cfg.TLS.CAFile,
+			CertFile:           cfg.TLS.CertFile,
+			KeyFile:            cfg.TLS.KeyFile,
+			MinVersion:         cfg.TLS.MinVersion,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+			Pins:               cfg.TLS.Pins,
+		}, Proxy: cfg.Proxy})
+		if err != nil {
+			return fmt.Errorf("configure TLS: %w", err)
+		}
+		if cfg.TLS.InsecureSkipVerify {
+			fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (insecure_skip_verify). Use only for local development.")
+		}
+
+		var refresher *auth.Refresher
+		var refresh func(context.Context, string) (string, error)
+		if auth.ExternalSource() == "" {
+			refresh = func(reqCtx context.Context, stale string) (string, error) {
+				return refresher.Refresh(reqCtx, stale)
+			}
+		}
+		identity := auth.CacheIdentity(cmd.Context(), store)
 		client := api.NewClient(api.ClientOptions{
 			BaseURL:   cfg.BaseURL,
+			Transport: transport,
 			Timeout:   20 * time.Second,
 			UserAgent: fmt.Sprintf("codemint/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH),
 			Debug:     flagDebug,
+			Redact:    auth.RedactToken,
+			Retry: api.RetryPolicy{
+				MaxAttempts: cfg.Retry.MaxAttempts,
+				BaseDelay:   cfg.Retry.BaseDelay.Std(),
+				MaxDelay:    cfg.Retry.MaxDelay.Std(),
+				Budget:      cfg.Retry.Budget.Std(),
+			},
+			Cache:           clientCache,
+			CacheTTL:        cfg.Cache.EffectiveTTL(),
+			Profile:         cfg.Profile,
+			Identity:        identity,
+			Org:             cfg.Org,
+			Offline:         cfg.Offline,
+			SyncConcurrency: cfg.Sync.Concurrency,
+			Refresh:         refresh,
 		})
+		refresher, err = auth.NewRefresher(cfg.Profile, store, client)
+		if err != nil {
+			return fmt.Errorf("init token refresh: %w", err)
+		}
 
-		ctx = appContext{Config: cfg, Client: client, Store: store, Mode: mode}
+		ctx = appContext{Config: cfg, Client: client, Store: store, Refresher: refresher, Cache: cache, Mode: mode}
 		return nil
 	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		exitCode := api.ExitCode(err)
+	reqCtx, stop := signalContext(context.Background())
+	err := rootCmd.ExecuteContext(reqCtx)
+	stop()
+	if err != nil && !errors.Is(err, context.Canceled) {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(exitCode)
+	}
+	if code := exitStatus(err); code != 0 {
+		os.Exit(code)
 	}
 }
 
@@ -75,8 +136,16 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "output JSON")
 	rootCmd.PersistentFlags().StringVar(&flagURL, "base-url", "", "override API base URL")
 	rootCmd.PersistentFlags().StringVar(&flagProf, "profile", "", "profile name")
+	rootCmd.PersistentFlags().StringVar(&flagOrg, "org", "", "organization slug for catalog requests")
 	rootCmd.PersistentFlags().StringVar(&cfgPath, "config", "", "config file path")
 	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "enable debug logging")
+	rootCmd.PersistentFlags().BoolVar(&flagOffln, "offline", false, "serve catalog data from the local cache only")
+	rootCmd.PersistentFlags().StringVar(&flagTLS.CAFile, "ca-file", "", "PEM CA bundle to trust in addition to system roots")
+	rootCmd.PersistentFlags().StringVar(&flagTLS.CertFile, "client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&flagTLS.KeyFile, "client-key", "", "PEM private key for --client-cert")
+	rootCmd.PersistentFlags().StringVar(&flagTLS.MinVersion, "tls-min-version", "", "minimum TLS version: 1.2 or 1.3")
+	rootCmd.PersistentFlags().BoolVar(&flagTLS.InsecureSkipVerify, "insecure-skip-verify", false, "disable TLS certificate verification (development only)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagTLS.Pins, "tls-pin", nil, "comma-separated sha256/<base64> public key pins for the server")
 
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newAuthCmd())
@@ -91,22 +160,79 @@ func init() {
 	rootCmd.AddCommand(newRemoveCmd())
 	rootCmd.AddCommand(newDoctorCmd())
 	rootCmd.AddCommand(newToolCmd())
+	rootCmd.AddCommand(newCacheCmd())
+	rootCmd.AddCommand(newAPICmd())
+	rootCmd.AddCommand(newDevServerCmd())
+	rootCmd.AddCommand(newProfileCmd())
+	rootCmd.AddCommand(newConfigCmd())
 }
 
-func rootContext() context.Context {
-	return context.Background()
+func tokenFromStore(reqCtx context.Context) (string, error) {
+	tok, _, err := activeToken(reqCtx)
+	return tok, err
 }
 
-func tokenFromStore() (string, error) {
-	if env := os.Getenv("CODEMINT_TOKEN"); strings.TrimSpace(env) != "" {
-		return strings.TrimSpace(env), nil
+// activeToken resolves the token for this run and reports where it came from.
+func activeToken(reqCtx context.Context) (string, string, error) {
+	cred, source, err := auth.ResolveCredential(reqCtx, ctx.Store, ctx.Client)
+	if source == auth.SourceEnv || source == auth.SourceFile || source == auth.SourceOIDC {
+		return cred.Token, source, err
+	}
+	if err != nil && ctx.Config.Offline {
+		// Offline reads never reach the server, so a missing token is fine.
+		return "", source, nil
 	}
-	tok, err := ctx.Store.Get(rootContext())
 	if err != nil {
 		if errors.Is(err, auth.ErrNotLoggedIn) {
-			return "", fmt.Errorf("not logged in: run `codemint auth login`")
+			return "", source, fmt.Errorf("not logged in: run `codemint auth login`")
 		}
-		return "", fmt.Errorf("not logged in. run `codemint auth login`: %w", err)
+		return "", source, fmt.Errorf("not logged in. run `codemint auth login`: %w", err)
+	}
+	warning, err := auth.CheckExpiry(cred)
+	if err != nil && cred.RefreshToken != "" {
+		if fresh, rerr := ctx.Refresher.Refresh(reqCtx, cred.Token); rerr == nil {
+			return fresh, source, nil
+		}
+	}
+	if err != nil {
+		return "", source, err
+	}
+	if warning != "" && cred.RefreshToken == "" {
+		fmt.Fprintln(os.Stderr, "Warning: "+warning)
+	}
+	return cred.Token, source, nil
+}
+
+// loadOptions collects the global flags and the current repository for
+// config.Load.
+func loadOptions() config.LoadOptions {
+	wd, _ := os.Getwd()
+	return config.LoadOptions{
+		ConfigPath:      cfgPath,
+		BaseURLOverride: flagURL,
+		ProfileOverride: flagProf,
+		Offline:         flagOffln,
+		TLSOverride:     flagTLS,
+		RepoDir:         wd,
+		OrgOverride:     flagOrg,
+	}
+}
+
+// tokenStoreFor opens the configured token store for a profile.
+func tokenStoreFor(cfg config.Config, profile string) (auth.TokenStore, error) {
+	return auth.NewStore(auth.StoreOptions{
+		Profile:    profile,
+		Backend:    cfg.TokenStore.Backend,
+		KeyFile:    cfg.TokenStore.KeyFile,
+		Passphrase: promptPassphrase,
+		Helper:     cfg.CredentialHelper,
+		BaseURL:    cfg.BaseURL,
+	})
+}
+
+// noteOffline tells the user that catalog answers came from the local cache.
+func noteOffline() {
+	if ctx.Config.Offline {
+		fmt.Fprintln(os.Stderr, "Offline mode: catalog data comes from the local cache and may be stale.")
 	}
-	return tok, nil
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+// exitInterrupted is the conventional exit status after SIGINT (128 + 2).
+const exitInterrupted = 130
+
+// exitStatus maps a command's error to the process exit status. Only a
+// command that stopped because it was cancelled exits with exitInterrupted;
+// one that finished before it noticed the signal keeps its own status.
+func exitStatus(err error) int {
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	return api.ExitCode(err)
+}
+
+// signalContext returns a context that is cancelled on the first SIGINT or
+// SIGTERM so commands can stop and clean up. A second signal exits at once.
+func signalContext(parent context.Context) (context.Context, func()) {
+	reqCtx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+		case <-done:
+			return
+		}
+		fmt.Fprintln(os.Stderr, "Interrupted; cleaning up (press Ctrl-C again to force quit)")
+		cancel()
+		select {
+		case <-sigs:
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+	return reqCtx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancel()
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalContextCancelsOnInterrupt(t *testing.T) {
+	reqCtx, stop := signalContext(context.Background())
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reqCtx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("context not cancelled after SIGINT")
+	}
+}
+
+func TestExitStatusIsInterruptedOnlyWhenTheCommandWasCancelled(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), 1},
+		{context.Canceled, exitInterrupted},
+		{fmt.Errorf("sync: %w", context.Canceled), exitInterrupted},
+	} {
+		if got := exitStatus(tc.err); got != tc.want {
+			t.Errorf("exitStatus(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func newSuggestCmd() *cobra.Command {
 		Use:   "suggest",
 		Short: "Suggest rules and skills based on repository scan",
 		RunE: func(c *cobra.Command, _ []string) error {
-			tok, err := tokenFromStore()
+			tok, err := tokenFromStore(c.Context())
 			if err != nil {
 				return err
 			}
@@ -49,6 +49,7 @@ func newSuggestCmd() *cobra.Command {
 					recs = append(recs, suggestItem{Ref: catalog.NormalizeRef(it.Type, it.Slug), Reason: reason, Tags: it.Tags})
 				}
 			}
+			noteOffline()
 
 			if ctx.Mode == output.ModeJSON {
 				return output.PrintJSON(map[string]any{"scan": res, "suggestions": recs})
@@ -17,15 +17,22 @@ type syncPlan struct {
 	Upgrade []manifest.Item `json:"upgrade"`
 	Same    []manifest.Item `json:"unchanged"`
 	Removed []manifest.Item `json:"removed"`
+	Failed  []syncFailure   `json:"failed"`
+}
+
+type syncFailure struct {
+	manifest.Item
+	Error string `json:"error"`
 }
 
 func newSyncCmd() *cobra.Command {
 	var dryRun bool
+	var failOnError bool
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Sync installed rules/skills with latest catalog versions",
 		RunE: func(c *cobra.Command, _ []string) error {
-			tok, err := tokenFromStore()
+			tok, err := tokenFromStore(c.Context())
 			if err != nil {
 				return err
 			}
@@ -53,12 +60,17 @@ func newSyncCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			noteOffline()
 
 			mgr := install.NewManager(wd)
 			plan := syncPlan{}
 			settings, _ := store.LoadSettings()
 			for _, local := range mf.Installed {
 				result := lookupSync(local.CatalogID, resp.Results)
+				if result != nil && result.Failed {
+					plan.Failed = append(plan.Failed, syncFailure{Item: local, Error: result.Error})
+					continue
+				}
 				if result == nil || result.Removed {
 					plan.Removed = append(plan.Removed, local)
 					continue
@@ -74,8 +86,12 @@ func newSyncCmd() *cobra.Command {
 				}
 				plan.Upgrade = append(plan.Upgrade, up)
 			}
+			applied := 0
 			if !dryRun {
 				for _, up := range plan.Upgrade {
+					if c.Context().Err() != nil {
+						break
+					}
 					result := lookupSync(up.CatalogID, resp.Results)
 					if result == nil {
 						continue
@@ -89,6 +105,12 @@ func newSyncCmd() *cobra.Command {
 						_, _ = fmt.Fprintf(os.Stderr, "sync: skip %s: %v\n", up.Slug, err)
 						continue
 					}
+					if c.Context().Err() != nil {
+						if err := mgr.Rollback(installed); err != nil {
+							_, _ = fmt.Fprintf(os.Stderr, "sync: rollback %s: %v\n", up.Slug, err)
+						}
+						break
+					}
 					up.Path = installed.Path
 					up.Tool = tool
 					if up.Checksum == "" {
@@ -99,21 +121,39 @@ func newSyncCmd() *cobra.Command {
 					if idx, ok := manifest.FindByCatalogID(mf.Installed, up.CatalogID); ok {
 						mf.Installed[idx] = up
 					}
+					applied++
 				}
 				if err := store.Save(mf); err != nil {
 					return err
 				}
+				if err := c.Context().Err(); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "sync: interrupted after %d of %d upgrade(s); manifest records completed upgrades only\n", applied, len(plan.Upgrade))
+					return err
+				}
 			}
 			if ctx.Mode == output.ModeJSON {
-				return output.PrintJSON(plan)
+				if err := output.PrintJSON(plan); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("Upgrades: %d\n", len(plan.Upgrade))
+				fmt.Printf("Unchanged: %d\n", len(plan.Same))
+				fmt.Printf("Removed/Deprecated: %d\n", len(plan.Removed))
+				if len(plan.Failed) > 0 {
+					fmt.Printf("Failed to check: %d\n", len(plan.Failed))
+					for _, f := range plan.Failed {
+						fmt.Printf("  %s: %s\n", f.Ref, f.Error)
+					}
+				}
+			}
+			if failOnError && len(plan.Failed) > 0 {
+				return fmt.Errorf("sync: %d item(s) could not be checked", len(plan.Failed))
 			}
-			fmt.Printf("Upgrades: %d\n", len(plan.Upgrade))
-			fmt.Printf("Unchanged: %d\n", len(plan.Same))
-			fmt.Printf("Removed/Deprecated: %d\n", len(plan.Removed))
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview sync plan without writing files")
+	cmd.Flags().BoolVar(&failOnError, "fail-on-error", false, "exit non-zero when any item could not be checked")
 	return cmd
 }
 
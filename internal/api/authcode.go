@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+type AuthCodeExchangeRequest struct {
+	Code         string `json:"code"`
+	CodeVerifier string `json:"codeVerifier"`
+	RedirectURI  string `json:"redirectUri"`
+}
+
+// ExchangeAuthCode trades a login callback's authorization code and PKCE
+// verifier for a CLI token.
+func (c *Client) ExchangeAuthCode(ctx context.Context, req AuthCodeExchangeRequest) (*CLIAuthCallbackPayload, error) {
+	var out CLIAuthCallbackPayload
+	if err := c.do(ctx, http.MethodPost, "/api/auth/cli-token/exchange", "", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshToken trades a refresh token for a new token pair.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*CLIAuthCallbackPayload, error) {
+	var out CLIAuthCallbackPayload
+	if err := c.do(ctx, http.MethodPost, "/api/auth/refresh", "", map[string]any{"refreshToken": refreshToken}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeRefreshToken invalidates a refresh token so it can no longer mint
+// new tokens. Unknown or already used refresh tokens are not an error.
+func (c *Client) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	return c.do(ctx, http.MethodPost, "/api/auth/refresh/revoke", "", map[string]any{"refreshToken": refreshToken}, nil)
+}
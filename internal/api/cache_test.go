@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/httpcache"
+)
+
+func TestCatalogResolveRevalidatesWithETag(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return jsonResponse(http.StatusOK, `{"type":"rule","slug":"safe-api","version":"1.0.0"}`, http.Header{"Etag": {`"v1"`}}), nil
+		}
+		if got := r.Header.Get("If-None-Match"); got != `"v1"` {
+			t.Fatalf("missing conditional header, got %q", got)
+		}
+		return jsonResponse(http.StatusNotModified, "", nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, Profile: "default", Identity: "email:dev@example.com"})
+	for i := 0; i < 2; i++ {
+		item, err := c.CatalogGetByRef(context.Background(), "tok", "rule", "safe-api")
+		if err != nil {
+			t.Fatalf("CatalogGetByRef error: %v", err)
+		}
+		if item.Version != "1.0.0" {
+			t.Fatalf("unexpected item: %+v", item)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected revalidation request, got %d calls", calls)
+	}
+}
+
+func TestCacheServesFreshEntriesWithoutRequest(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(http.StatusOK, `{"data":[{"id":"i1","name":"Alpha","type":"rule"}],"page":1,"limit":20,"total":1}`, nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, CacheTTL: time.Minute, Identity: "email:dev@example.com"})
+	for i := 0; i < 3; i++ {
+		if _, err := c.ItemsSearch(context.Background(), "tok", ItemsSearchRequest{Q: "alpha"}); err != nil {
+			t.Fatalf("ItemsSearch error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected one network call, got %d", calls)
+	}
+	info, err := store.Info()
+	if err != nil || info.Entries != 1 {
+		t.Fatalf("unexpected cache info %+v err=%v", info, err)
+	}
+}
+
+func TestOrgScopesCatalogRequestsAndCache(t *testing.T) {
+	var orgs []string
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/api/auth/me" {
+			if r.Header.Get(OrgHeader) != "" {
+				t.Errorf("org header sent on %s", r.URL.Path)
+			}
+			return jsonResponse(http.StatusOK, `{"user":{"id":"u1"}}`, nil), nil
+		}
+		orgs = append(orgs, r.Header.Get(OrgHeader))
+		return jsonResponse(http.StatusOK, `{"type":"rule","slug":"safe-api","version":"1.0.0"}`, nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	for _, org := range []string{"", "acme", "acme"} {
+		c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, CacheTTL: time.Minute, Profile: "default", Identity: "email:dev@example.com", Org: org})
+		if _, err := c.CatalogGetByRef(context.Background(), "tok", "rule", "safe-api"); err != nil {
+			t.Fatalf("CatalogGetByRef: %v", err)
+		}
+		if _, err := c.AuthMe(context.Background(), "tok"); err != nil {
+			t.Fatalf("AuthMe: %v", err)
+		}
+	}
+	// The personal and acme responses are cached separately; the second
+	// acme lookup is served from the cache.
+	if len(orgs) != 2 || orgs[0] != "" || orgs[1] != "acme" {
+		t.Fatalf("catalog requests sent org headers %q", orgs)
+	}
+}
+
+func TestCacheIsScopedToTheAccount(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(http.StatusOK, `{"type":"rule","slug":"safe-api","version":"1.0.0"}`, nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	for i, identity := range []string{"email:a@example.com", "email:a@example.com", "email:b@example.com", "", ""} {
+		c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, CacheTTL: time.Minute, Profile: "default", Identity: identity})
+		if _, err := c.CatalogGetByRef(context.Background(), "tok", "rule", "safe-api"); err != nil {
+			t.Fatalf("lookup %d: %v", i, err)
+		}
+	}
+	// Only the second lookup, by the same account, is served from the
+	// cache; without an identity nothing is cached or served.
+	if calls != 4 {
+		t.Fatalf("expected 4 network calls, got %d", calls)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIVersion is the backend API revision this CLI speaks. It is sent on every
+// request in the APIVersionHeader header.
+const (
+	APIVersion       = 1
+	APIVersionHeader = "X-CodeMint-API-Version"
+)
+
+// OrgHeader names the organization whose catalog a request addresses.
+const OrgHeader = "X-CodeMint-Org"
+
+// Features the server may advertise in Capabilities.Features.
+const (
+	// FeatureOrgListWrapped means /api/org/my always returns
+	// {"organizations": [...]}.
+	FeatureOrgListWrapped = "org.list.wrapped"
+	// FeatureCatalogFields means search results carry slug, catalogId,
+	// version, checksum, content, applyMode and globs as top-level fields,
+	// so the metadata fallbacks older servers need are skipped.
+	FeatureCatalogFields = "catalog.fields"
+)
+
+const capabilitiesTTL = 24 * time.Hour
+
+// Capabilities is the server's answer to GET /api/meta.
+type Capabilities struct {
+	APIVersion    int      `json:"apiVersion"`
+	MinAPIVersion int      `json:"minApiVersion"`
+	MaxAPIVersion int      `json:"maxApiVersion"`
+	MinCLIVersion string   `json:"minCliVersion,omitempty"`
+	Features      []string `json:"features"`
+	// Legacy is set when the server predates the meta endpoint.
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+func (c *Capabilities) Has(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionError reports that the CLI and server cannot talk to each other.
+type VersionError struct {
+	ClientVersion int
+	Server        Capabilities
+}
+
+func (e *VersionError) Error() string {
+	if e.Server.MinAPIVersion > e.ClientVersion {
+		msg := fmt.Sprintf("this CodeMint server requires API v%d or newer but this CLI speaks v%d; upgrade the CLI", e.Server.MinAPIVersion, e.ClientVersion)
+		if e.Server.MinCLIVersion != "" {
+			msg += " to " + e.Server.MinCLIVersion + " or later"
+		}
+		return msg + " (curl -fsSL https://raw.githubusercontent.com/neghani/code-mint-cli/main/install.sh | sh)"
+	}
+	return fmt.Sprintf("this CodeMint server only supports API up to v%d but this CLI speaks v%d; ask your administrator to upgrade the server or install an older CLI", e.Server.MaxAPIVersion, e.ClientVersion)
+}
+
+func (c Capabilities) compatible() error {
+	if c.Legacy {
+		return nil
+	}
+	if (c.MinAPIVersion > 0 && c.MinAPIVersion > APIVersion) || (c.MaxAPIVersion > 0 && c.MaxAPIVersion < APIVersion) {
+		return &VersionError{ClientVersion: APIVersion, Server: c}
+	}
+	return nil
+}
+
+// Capabilities discovers what the server supports. The answer is fetched at
+// most once per client and cached on disk per base URL. Servers without the
+// meta endpoint are reported as Legacy.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+	if c.caps != nil {
+		return c.caps, c.caps.compatible()
+	}
+	caps := c.loadCapabilities(ctx)
+	c.caps = &caps
+	return c.caps, caps.compatible()
+}
+
+func (c *Client) loadCapabilities(ctx context.Context) Capabilities {
+	var caps Capabilities
+	if c.meta != nil {
+		if storedAt, ok := c.meta.GetObject("meta", c.baseURL, &caps); ok && (c.offline || time.Since(storedAt) < capabilitiesTTL) {
+			return caps
+		}
+	}
+	if c.offline {
+		return Capabilities{Legacy: true}
+	}
+	caps = Capabilities{}
+	err := c.send(ctx, request{method: http.MethodGet, path: "/api/meta", out: &caps, negotiating: true})
+	var aerr *APIError
+	switch {
+	case err == nil && caps.APIVersion > 0:
+	case err == nil, errors.As(err, &aerr) && (aerr.Status == http.StatusNotFound || aerr.Status == http.StatusMethodNotAllowed):
+		caps = Capabilities{Legacy: true}
+	default:
+		// Do not cache transient failures; fall back to legacy behavior for
+		// this session only.
+		c.debugf("capabilities lookup failed: %v", err)
+		return Capabilities{Legacy: true}
+	}
+	if c.meta != nil {
+		_ = c.meta.PutObject("meta", c.baseURL, caps)
+	}
+	return caps
+}
+
+// Supports reports whether the server advertises the feature. Unknown or
+// unreachable servers support nothing beyond the legacy API.
+func (c *Client) Supports(ctx context.Context, feature string) bool {
+	caps, err := c.Capabilities(ctx)
+	return err == nil && caps.Has(feature)
+}
+
+// versionProblem converts a failed exchange into an actionable VersionError
+// when the server's advertised range excludes this CLI.
+func (c *Client) versionProblem(ctx context.Context, r request, cause error) error {
+	if r.negotiating {
+		return cause
+	}
+	if _, err := c.Capabilities(ctx); err != nil {
+		return err
+	}
+	return cause
+}
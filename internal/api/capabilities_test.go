@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCapabilitiesFetchedOncePerSession(t *testing.T) {
+	metaCalls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get(APIVersionHeader) != "1" {
+			t.Fatalf("missing API version header on %s", r.URL.Path)
+		}
+		if r.URL.Path == "/api/meta" {
+			metaCalls++
+			return jsonResponse(http.StatusOK, `{"apiVersion":1,"minApiVersion":1,"maxApiVersion":2,"features":["org.list.wrapped"]}`, nil), nil
+		}
+		return jsonResponse(http.StatusOK, `{"organizations":[{"id":"o1","slug":"acme"}]}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	for i := 0; i < 2; i++ {
+		orgs, err := c.OrgList(context.Background(), "tok")
+		if err != nil || len(orgs.Organizations) != 1 {
+			t.Fatalf("OrgList: %+v err=%v", orgs, err)
+		}
+	}
+	if metaCalls != 1 {
+		t.Fatalf("expected one capabilities lookup, got %d", metaCalls)
+	}
+}
+
+func TestTooNewServerGivesUpgradeMessage(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/api/meta" {
+			return jsonResponse(http.StatusOK, `{"apiVersion":3,"minApiVersion":2,"maxApiVersion":3,"minCliVersion":"2.0.0"}`, nil), nil
+		}
+		return jsonResponse(http.StatusUpgradeRequired, `{"error":"upgrade required"}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	_, err := c.AuthMe(context.Background(), "tok")
+	var verr *VersionError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected VersionError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "upgrade the CLI to 2.0.0") {
+		t.Fatalf("message is not actionable: %v", err)
+	}
+}
+
+func TestLegacyServerWithoutMeta(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/api/meta" {
+			return jsonResponse(http.StatusNotFound, `{"error":"not found"}`, nil), nil
+		}
+		return jsonResponse(http.StatusOK, `[{"id":"o1","slug":"acme"}]`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	orgs, err := c.OrgList(context.Background(), "tok")
+	if err != nil || len(orgs.Organizations) != 1 {
+		t.Fatalf("OrgList: %+v err=%v", orgs, err)
+	}
+	caps, err := c.Capabilities(context.Background())
+	if err != nil || !caps.Legacy {
+		t.Fatalf("expected legacy capabilities, got %+v err=%v", caps, err)
+	}
+}
+
+func TestCatalogMetadataFallbackOnlyForLegacyServers(t *testing.T) {
+	item := `{"data":[{"id":"i1","type":"rule","name":"Go","metadata":{"slug":"go-errors","catalogVersion":"1.2.0","content":"meta body"}}],"page":1,"limit":50,"total":1}`
+	for _, tc := range []struct {
+		meta        string
+		wantSlug    string
+		wantVersion string
+	}{
+		{meta: `{"apiVersion":1,"features":["catalog.fields"]}`, wantSlug: "i1", wantVersion: "0.0.0"},
+		{meta: "", wantSlug: "go-errors", wantVersion: "1.2.0"},
+	} {
+		transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path == "/api/meta" {
+				if tc.meta == "" {
+					return jsonResponse(http.StatusNotFound, `{"error":"not found"}`, nil), nil
+				}
+				return jsonResponse(http.StatusOK, tc.meta, nil), nil
+			}
+			return jsonResponse(http.StatusOK, item, nil), nil
+		})
+		c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+		items, err := c.CatalogSuggest(context.Background(), "tok", CatalogLookupRequest{Q: "go", Type: "rule"})
+		if err != nil || len(items) != 1 {
+			t.Fatalf("meta %q: CatalogSuggest = %+v, %v", tc.meta, items, err)
+		}
+		if got := items[0]; got.Slug != tc.wantSlug || got.Version != tc.wantVersion || got.CatalogID != "rule:"+tc.wantSlug {
+			t.Fatalf("meta %q: got slug=%q version=%q catalogId=%q", tc.meta, got.Slug, got.Version, got.CatalogID)
+		}
+	}
+}
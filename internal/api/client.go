@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/codemint/codemint-cli/internal/httpcache"
 )
 
 type ClientOptions struct {
@@ -18,13 +23,54 @@ type ClientOptions struct {
 	Timeout   time.Duration
 	UserAgent string
 	Debug     bool
+	// DebugOutput receives the --debug trace; it defaults to stderr.
+	DebugOutput io.Writer
+	// Redact scrubs credentials from every traced line.
+	Redact    func(string) string
 	Transport http.RoundTripper
+	Retry     RetryPolicy
+	// Cache stores catalog read responses; nil disables caching.
+	Cache *httpcache.Store
+	// CacheTTL is how long a cached response is served without revalidation.
+	CacheTTL time.Duration
+	// Profile scopes cache entries so profiles never share responses.
+	Profile string
+	// Identity names the account cached responses belong to, such as its
+	// email or a hash of its token. Without one, catalog responses are not
+	// cached, so they are never served to another account or to no one.
+	Identity string
+	// Org is the organization slug sent with catalog requests; empty means
+	// the user's default scope.
+	Org string
+	// Offline answers catalog reads from the local cache and never touches
+	// the network.
+	Offline bool
+	// SyncConcurrency caps how many catalog sync batches are in flight.
+	SyncConcurrency int
+	// Refresh is called once when an authenticated request gets a 401. It
+	// returns a replacement for the stale token, and the request is replayed
+	// with it.
+	Refresh func(ctx context.Context, staleToken string) (string, error)
 }
 
 type Client struct {
-	baseURL string
-	http    *http.Client
-	debug   bool
+	baseURL  string
+	http     *http.Client
+	trace    *debugRoundTripper
+	retry    RetryPolicy
+	cache    *httpcache.Store
+	cacheTTL time.Duration
+	// meta caches server capabilities, which do not depend on the account.
+	meta     *httpcache.Store
+	profile  string
+	identity string
+	org      string
+	offline  bool
+	workers  int
+	refresh  func(ctx context.Context, staleToken string) (string, error)
+
+	capsMu sync.Mutex
+	caps   *Capabilities
 }
 
 func NewClient(opts ClientOptions) *Client {
@@ -32,6 +78,17 @@ func NewClient(opts ClientOptions) *Client {
 	if opts.Transport != nil {
 		tr = opts.Transport
 	}
+	var trace *debugRoundTripper
+	if opts.Debug {
+		trace = &debugRoundTripper{next: tr, out: opts.DebugOutput, redact: opts.Redact}
+		if trace.out == nil {
+			trace.out = os.Stderr
+		}
+		if trace.redact == nil {
+			trace.redact = fallbackRedact
+		}
+		tr = trace
+	}
 	if opts.UserAgent != "" {
 		tr = uaRoundTripper{next: tr, userAgent: opts.UserAgent}
 	}
@@ -39,10 +96,34 @@ func NewClient(opts ClientOptions) *Client {
 	if timeout <= 0 {
 		timeout = 20 * time.Second
 	}
+	workers := opts.SyncConcurrency
+	if workers <= 0 {
+		workers = 4
+	}
+	cache := opts.Cache
+	if opts.Identity == "" {
+		cache = nil
+	}
 	return &Client{
-		baseURL: strings.TrimRight(opts.BaseURL, "/"),
-		http:    &http.Client{Timeout: timeout, Transport: tr},
-		debug:   opts.Debug,
+		baseURL:  strings.TrimRight(opts.BaseURL, "/"),
+		http:     &http.Client{Timeout: timeout, Transport: tr},
+		trace:    trace,
+		retry:    opts.Retry.withDefaults(),
+		cache:    cache,
+		cacheTTL: opts.CacheTTL,
+		meta:     opts.Cache,
+		profile:  opts.Profile,
+		identity: opts.Identity,
+		org:      opts.Org,
+		offline:  opts.Offline,
+		workers:  workers,
+		refresh:  opts.Refresh,
+	}
+}
+
+func (c *Client) debugf(format string, args ...any) {
+	if c.trace != nil {
+		c.trace.logf(format, args...)
 	}
 }
 
@@ -54,6 +135,17 @@ func (c *Client) AuthMe(ctx context.Context, token string) (*AuthMeResponse, err
 	return &out.User, nil
 }
 
+// VerifyToken is AuthMe for a token that is not stored yet, such as one just
+// entered at login. A 401 is returned as is rather than refreshed and
+// replayed with the stored credential.
+func (c *Client) VerifyToken(ctx context.Context, token string) (*AuthMeResponse, error) {
+	var out authMeEnvelope
+	if err := c.send(ctx, request{method: http.MethodGet, path: "/api/auth/me", token: token, out: &out, refreshed: true}); err != nil {
+		return nil, err
+	}
+	return &out.User, nil
+}
+
 func (c *Client) ItemsSearch(ctx context.Context, token string, req ItemsSearchRequest) (*ItemsSearchResponse, error) {
 	q := url.Values{}
 	if req.Q != "" {
@@ -82,84 +174,171 @@ func (c *Client) ItemsSearch(ctx context.Context, token string, req ItemsSearchR
 		path += "?" + enc
 	}
 	var out ItemsSearchResponse
-	if err := c.do(ctx, http.MethodGet, path, token, nil, &out); err != nil {
+	if err := c.send(ctx, request{method: http.MethodGet, path: path, token: token, out: &out, cacheable: true, catalog: true}); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 func (c *Client) CatalogSuggest(ctx context.Context, token string, req CatalogLookupRequest) ([]CatalogItem, error) {
-	resp, err := c.ItemsSearch(ctx, token, ItemsSearchRequest{Q: req.Q, Type: req.Type, Tags: req.Tags, Latest: true, Page: 1, Limit: 50})
-	if err != nil {
-		return nil, err
+	if c.offline {
+		return c.offlineSuggest(req)
+	}
+	it := c.SearchAll(token, ItemsSearchRequest{Q: req.Q, Type: req.Type, Tags: req.Tags, Latest: true, Limit: 50}, 0)
+	legacy := !c.Supports(ctx, FeatureCatalogFields)
+	out := make([]CatalogItem, 0)
+	for it.Next(ctx) {
+		out = append(out, itemToCatalog(it.Item(), legacy))
 	}
-	out := make([]CatalogItem, 0, len(resp.Data))
-	for _, it := range resp.Data {
-		out = append(out, itemToCatalog(it))
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
+	c.remember(out...)
 	return out, nil
 }
 
 func (c *Client) CatalogGetByRef(ctx context.Context, token string, itemType, slug string) (*CatalogItem, error) {
+	if c.offline {
+		return c.offlineGetByRef(itemType, slug)
+	}
 	ref := url.QueryEscape("@" + itemType + "/" + slug)
 	var out CatalogItem
-	if err := c.do(ctx, http.MethodGet, "/api/catalog/resolve?ref="+ref, token, nil, &out); err != nil {
+	if err := c.send(ctx, request{method: http.MethodGet, path: "/api/catalog/resolve?ref=" + ref, token: token, out: &out, cacheable: true, catalog: true}); err != nil {
 		return nil, err
 	}
 	normalizeCatalogItem(&out)
+	c.remember(out)
 	return &out, nil
 }
 
 func (c *Client) CatalogSync(ctx context.Context, token string, req CatalogSyncRequest) (*CatalogSyncResponse, error) {
+	if c.offline {
+		return c.offlineSync(req)
+	}
 	const maxBatch = 100
-	remoteMap := make(map[string]*CatalogItem)
+	type batch struct {
+		items  []CatalogSyncItem
+		remote []*CatalogItem
+		err    error
+	}
+	batches := make([]batch, 0, (len(req.Items)+maxBatch-1)/maxBatch)
 	for start := 0; start < len(req.Items); start += maxBatch {
 		end := start + maxBatch
 		if end > len(req.Items) {
 			end = len(req.Items)
 		}
-		ids := make([]string, 0, end-start)
-		for _, it := range req.Items[start:end] {
-			ids = append(ids, it.CatalogID)
+		batches = append(batches, batch{items: req.Items[start:end]})
+	}
+
+	sem := make(chan struct{}, c.workers)
+	var wg sync.WaitGroup
+	for i := range batches {
+		wg.Add(1)
+		go func(b *batch) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				b.err = err
+				return
+			}
+			ids := make([]string, 0, len(b.items))
+			for _, it := range b.items {
+				ids = append(ids, it.CatalogID)
+			}
+			var apiOut catalogSyncAPIResponse
+			b.err = c.send(ctx, request{method: http.MethodPost, path: "/api/catalog/sync", token: token, in: map[string]any{"catalogIds": ids}, out: &apiOut, idempotent: true, cacheable: true, catalog: true})
+			b.remote = apiOut.Items
+		}(&batches[i])
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Errors about the request as a whole, such as an expired token, would
+	// be the same for every item, so they are returned instead of a list of
+	// identical per-item failures.
+	for _, b := range batches {
+		if affectsEveryItem(b.err) {
+			return nil, b.err
 		}
-		var apiOut catalogSyncAPIResponse
-		if err := c.do(ctx, http.MethodPost, "/api/catalog/sync", token, map[string]any{"catalogIds": ids}, &apiOut); err != nil {
-			return nil, err
+	}
+
+	results := make([]CatalogSyncResult, 0, len(req.Items))
+	fetched := make([]CatalogItem, 0, len(req.Items))
+	for _, b := range batches {
+		if b.err != nil {
+			for _, local := range b.items {
+				results = append(results, failedSyncResult(local, b.err))
+			}
+			continue
 		}
-		for _, item := range apiOut.Items {
+		remoteMap := make(map[string]*CatalogItem, len(b.remote))
+		for _, item := range b.remote {
 			if item != nil && item.CatalogID != "" {
 				remoteMap[item.CatalogID] = item
 			}
 		}
+		for _, local := range b.items {
+			remote := remoteMap[local.CatalogID]
+			if remote != nil {
+				normalizeCatalogItem(remote)
+				fetched = append(fetched, *remote)
+			}
+			results = append(results, syncResult(local, remote))
+		}
 	}
+	c.remember(fetched...)
+	return &CatalogSyncResponse{Results: results}, nil
+}
 
-	results := make([]CatalogSyncResult, 0, len(req.Items))
-	for _, local := range req.Items {
-		remote := remoteMap[local.CatalogID]
-		if remote == nil {
-			results = append(results, CatalogSyncResult{
-				CatalogID:      local.CatalogID,
-				CurrentVersion: local.Version,
-				Removed:        true,
-			})
-			continue
-		}
-		normalizeCatalogItem(remote)
-		results = append(results, CatalogSyncResult{
+// affectsEveryItem reports whether err is about the credentials, access or
+// connection rather than the items in one sync batch.
+func affectsEveryItem(err error) bool {
+	var verr *VersionError
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrOffline) || errors.As(err, &verr)
+}
+
+func failedSyncResult(local CatalogSyncItem, err error) CatalogSyncResult {
+	return CatalogSyncResult{
+		CatalogID:      local.CatalogID,
+		CurrentVersion: local.Version,
+		Failed:         true,
+		Error:          err.Error(),
+	}
+}
+
+func syncResult(local CatalogSyncItem, remote *CatalogItem) CatalogSyncResult {
+	if remote == nil {
+		return CatalogSyncResult{
 			CatalogID:      local.CatalogID,
-			Slug:           remote.Slug,
-			Type:           remote.Type,
 			CurrentVersion: local.Version,
-			LatestVersion:  remote.Version,
-			Deprecated:     remote.Deprecated,
-			Removed:        false,
-			LatestItem:     *remote,
-		})
+			Removed:        true,
+		}
+	}
+	normalizeCatalogItem(remote)
+	return CatalogSyncResult{
+		CatalogID:      local.CatalogID,
+		Slug:           remote.Slug,
+		Type:           remote.Type,
+		CurrentVersion: local.Version,
+		LatestVersion:  remote.Version,
+		Deprecated:     remote.Deprecated,
+		Removed:        false,
+		LatestItem:     *remote,
 	}
-	return &CatalogSyncResponse{Results: results}, nil
 }
 
 func (c *Client) OrgList(ctx context.Context, token string) (*OrgListResponse, error) {
+	if c.Supports(ctx, FeatureOrgListWrapped) {
+		var out OrgListResponse
+		if err := c.do(ctx, http.MethodGet, "/api/org/my", token, nil, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+	// Older servers return either a bare array or the wrapped object.
 	var raw json.RawMessage
 	if err := c.do(ctx, http.MethodGet, "/api/org/my", token, nil, &raw); err != nil {
 		return nil, err
@@ -172,68 +351,212 @@ func (c *Client) OrgList(ctx context.Context, token string) (*OrgListResponse, e
 	if err := json.Unmarshal(raw, &wrapped); err == nil {
 		return &wrapped, nil
 	}
-	return nil, fmt.Errorf("decode org list response: unexpected payload shape")
+	return nil, c.versionProblem(ctx, request{}, fmt.Errorf("decode org list response: unexpected payload shape"))
+}
+
+type request struct {
+	method string
+	path   string
+	token  string
+	in     any
+	out    any
+	// idempotent marks non-GET requests that are safe to replay, such as the
+	// read-only POST used by catalog sync.
+	idempotent bool
+	// cacheable responses are stored on disk and revalidated with ETags.
+	cacheable bool
+	// catalog requests carry the active org in OrgHeader.
+	catalog bool
+	// body is sent as-is instead of JSON-encoding in.
+	body []byte
+	// capture receives the raw successful response instead of decoding out.
+	capture *RawResponse
+	// negotiating marks the capabilities lookup itself, which must not
+	// trigger another lookup on failure.
+	negotiating bool
+	// refreshed is set on the replay after a token refresh so a second 401
+	// is returned to the caller. VerifyToken and RevokeCurrentCLIToken set
+	// it up front so the token they were given is never swapped for another.
+	refreshed bool
 }
 
 func (c *Client) do(ctx context.Context, method, path, token string, in any, out any) error {
-	var payload []byte
+	return c.send(ctx, request{method: method, path: path, token: token, in: in, out: out})
+}
+
+func (c *Client) send(ctx context.Context, r request) error {
+	payload := r.body
 	var err error
-	if in != nil {
-		payload, err = json.Marshal(in)
+	if r.in != nil {
+		payload, err = json.Marshal(r.in)
 		if err != nil {
 			return err
 		}
 	}
 
-	retries := 2
+	var cached httpcache.Entry
+	var hasCached bool
+	cacheKey := ""
+	if c.cache != nil && r.cacheable {
+		cacheKey = httpcache.Key(append(c.scope(), r.method, r.path, string(payload))...)
+		cached, hasCached = c.cache.Get(cacheKey)
+		if hasCached && (c.offline || c.cacheTTL > 0 && cached.Age() < c.cacheTTL) {
+			c.debugf("cache hit %s %s (age %s)", r.method, r.path, cached.Age().Round(time.Second))
+			return decodeBody(cached.Body, r.out)
+		}
+	}
+
+	if c.offline {
+		return fmt.Errorf("%w: %s %s needs the network", ErrOffline, r.method, stripQuery(r.path))
+	}
+
+	canRetry := r.idempotent || idempotentMethod(r.method)
+	started := now()
 	var lastErr error
-	for attempt := 0; attempt <= retries; attempt++ {
+	for attempt := 1; ; attempt++ {
 		var body io.Reader
 		if len(payload) > 0 {
 			body = bytes.NewReader(payload)
 		}
-		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+		req, err := http.NewRequestWithContext(ctx, r.method, c.baseURL+r.path, body)
 		if err != nil {
 			return err
 		}
+		req.Header.Set(APIVersionHeader, strconv.Itoa(APIVersion))
+		if r.catalog && c.org != "" {
+			req.Header.Set(OrgHeader, c.org)
+		}
 		if len(payload) > 0 {
 			req.Header.Set("Content-Type", "application/json")
 		}
-		if token != "" {
-			req.Header.Set("Authorization", "Bearer "+token)
+		if r.token != "" {
+			req.Header.Set("Authorization", "Bearer "+r.token)
 		}
-		resp, err := c.http.Do(req)
-		if err != nil {
-			if transient(err) && attempt < retries {
-				time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
-				lastErr = err
-				continue
+		if hasCached {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
 			}
-			return err
 		}
-		if resp.StatusCode >= 400 {
+		var hint time.Duration
+		var hasHint bool
+		resp, err := c.http.Do(req)
+		switch {
+		case err != nil:
+			if !transient(err) || ctx.Err() != nil {
+				return err
+			}
+			lastErr = err
+		case resp.StatusCode == http.StatusNotModified && hasCached:
+			_ = resp.Body.Close()
+			c.debugf("cache revalidated %s %s", r.method, r.path)
+			cached.StoredAt = time.Now().UTC()
+			_ = c.cache.Put(cached)
+			return decodeBody(cached.Body, r.out)
+		case resp.StatusCode >= 400:
 			b, _ := io.ReadAll(resp.Body)
 			_ = resp.Body.Close()
-			aerr := parseAPIError(resp.StatusCode, b)
-			if resp.StatusCode >= 500 && attempt < retries {
-				time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
-				lastErr = aerr
-				continue
+			aerr := parseAPIError(resp.StatusCode, resp.Header, b)
+			if resp.StatusCode == http.StatusNotAcceptable || resp.StatusCode == http.StatusUpgradeRequired {
+				return c.versionProblem(ctx, r, aerr)
 			}
-			return aerr
-		}
-		if out == nil {
+			if resp.StatusCode == http.StatusUnauthorized && c.refresh != nil && r.token != "" && !r.refreshed {
+				fresh, err := c.refresh(ctx, r.token)
+				if err != nil {
+					c.debugf("token refresh failed: %v", err)
+					return aerr
+				}
+				c.debugf("token refreshed; replaying %s %s", r.method, stripQuery(r.path))
+				r.token, r.refreshed = fresh, true
+				return c.send(ctx, r)
+			}
+			if !retryableStatus(resp.StatusCode) {
+				return aerr
+			}
+			hint, hasHint = retryAfter(resp.Header)
+			lastErr = aerr
+		case r.capture != nil:
+			b, err := io.ReadAll(resp.Body)
 			_ = resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			*r.capture = RawResponse{Status: resp.StatusCode, Proto: resp.Proto, Header: resp.Header, Body: b}
 			return nil
+		default:
+			if cacheKey == "" {
+				if r.out == nil {
+					_ = resp.Body.Close()
+					return nil
+				}
+				err = json.NewDecoder(resp.Body).Decode(r.out)
+				_ = resp.Body.Close()
+				if err != nil {
+					return c.versionProblem(ctx, r, fmt.Errorf("decode %s response: %w", stripQuery(r.path), err))
+				}
+				return nil
+			}
+			b, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			if err := decodeBody(b, r.out); err != nil {
+				return c.versionProblem(ctx, r, fmt.Errorf("decode %s response: %w", stripQuery(r.path), err))
+			}
+			_ = c.cache.Put(httpcache.Entry{
+				Key:          cacheKey,
+				URL:          c.baseURL + r.path,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				Body:         b,
+			})
+			return nil
+		}
+
+		if !canRetry || attempt >= c.retry.MaxAttempts {
+			return lastErr
+		}
+		delay := c.retry.backoff(attempt - 1)
+		if hasHint {
+			delay = hint
+		}
+		if now().Add(delay).Sub(started) > c.retry.Budget {
+			c.debugf("retry budget %s exhausted after %d attempt(s): %v", c.retry.Budget, attempt, lastErr)
+			return lastErr
 		}
-		err = json.NewDecoder(resp.Body).Decode(out)
-		_ = resp.Body.Close()
-		return err
+		c.debugf("attempt %d/%d failed (%v); retrying in %s", attempt, c.retry.MaxAttempts, lastErr, delay.Round(time.Millisecond))
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func stripQuery(path string) string {
+	return strings.SplitN(path, "?", 2)[0]
+}
+
+func decodeBody(b []byte, out any) error {
+	if out == nil {
+		return nil
 	}
-	return lastErr
+	return json.Unmarshal(b, out)
 }
 
-func parseAPIError(status int, b []byte) error {
+func parseAPIError(status int, header http.Header, b []byte) *APIError {
+	aerr := parseErrorBody(status, b)
+	if aerr.RequestID == "" {
+		aerr.RequestID = requestIDFrom(header)
+	}
+	if d, ok := retryAfter(header); ok {
+		aerr.RetryAfter = d
+	}
+	return aerr
+}
+
+func parseErrorBody(status int, b []byte) *APIError {
 	appendAuthHint := func(m string) string {
 		if status == 401 || status == 403 {
 			return m + " — run 'codemint auth login' to re-authenticate"
@@ -241,16 +564,30 @@ func parseAPIError(status int, b []byte) error {
 		return m
 	}
 	var env ErrorEnvelope
-	if err := json.Unmarshal(b, &env); err == nil && env.Error.Message != "" {
-		return &APIError{Status: status, Code: env.Error.Code, Message: appendAuthHint(env.Error.Message)}
+	if err := json.Unmarshal(b, &env); err == nil && (env.Error.Message != "" || env.Error.Code != "") {
+		msg := env.Error.Message
+		if msg == "" {
+			msg = env.Error.Code
+		}
+		return &APIError{Status: status, Code: env.Error.Code, Message: appendAuthHint(msg), RequestID: env.Error.RequestID}
 	}
 	var flat map[string]any
 	if err := json.Unmarshal(b, &flat); err == nil {
+		requestID, _ := flat["requestId"].(string)
 		if msg, ok := flat["error"].(string); ok && msg != "" {
-			return &APIError{Status: status, Message: appendAuthHint(msg)}
+			// OAuth endpoints (RFC 6749 section 5.2, RFC 8628) send the code
+			// in "error" and the text in "error_description".
+			code := ""
+			if isErrorCode(msg) {
+				code = msg
+			}
+			if desc, _ := flat["error_description"].(string); desc != "" {
+				msg = desc
+			}
+			return &APIError{Status: status, Code: code, Message: appendAuthHint(msg), RequestID: requestID}
 		}
 		if msg, ok := flat["message"].(string); ok && msg != "" {
-			return &APIError{Status: status, Message: appendAuthHint(msg)}
+			return &APIError{Status: status, Message: appendAuthHint(msg), RequestID: requestID}
 		}
 	}
 	msg := strings.TrimSpace(string(b))
@@ -260,79 +597,70 @@ func parseAPIError(status int, b []byte) error {
 	return &APIError{Status: status, Message: appendAuthHint(msg)}
 }
 
-func transient(err error) bool {
-	if nerr, ok := err.(net.Error); ok {
-		return nerr.Timeout()
+// isErrorCode reports whether s looks like a machine-readable error code
+// such as "authorization_pending" rather than a sentence.
+func isErrorCode(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
 	}
-	return false
+	return s != ""
 }
 
-func itemToCatalog(it Item) CatalogItem {
-	slug := it.Slug
-	if slug == "" {
-		slug = strMeta(it.Metadata, "slug")
-	}
-	catalogID := it.CatalogID
-	if catalogID == "" {
-		catalogID = strMeta(it.Metadata, "catalogId")
-	}
-	version := it.Version
-	if version == "" {
-		version = it.CatVer
-	}
-	if version == "" {
-		version = strMeta(it.Metadata, "catalogVersion")
-	}
-	if version == "" {
-		version = strMeta(it.Metadata, "version")
-	}
-	checksum := it.Checksum
-	if checksum == "" {
-		checksum = strMeta(it.Metadata, "checksum")
-	}
-	content := it.Content
-	if content == "" {
-		content = strMeta(it.Metadata, "content")
-	}
-	name := it.Name
-	if name == "" {
-		name = it.Title
+// itemToCatalog converts a search result. legacy servers may keep catalog
+// fields only in metadata, so they are read from there when missing.
+func itemToCatalog(it Item, legacy bool) CatalogItem {
+	if legacy {
+		it = itemFromMetadata(it)
 	}
-	if version == "" {
-		version = "0.0.0"
-	}
-	if slug == "" {
-		slug = it.ID
-	}
-	if catalogID == "" {
-		catalogID = it.Type + ":" + slug
-	}
-	applyMode := it.ApplyMode
-	if applyMode == "" {
-		applyMode = strMeta(it.Metadata, "applyMode")
-	}
-	globs := it.Globs
-	if globs == "" {
-		globs = strMeta(it.Metadata, "globs")
-	}
-	return CatalogItem{
+	out := CatalogItem{
 		ID:         it.ID,
 		Title:      it.Title,
-		Name:       name,
+		Name:       it.Name,
 		Type:       it.Type,
-		Slug:       slug,
-		CatalogID:  catalogID,
-		Version:    version,
-		CatVer:     version,
-		Checksum:   checksum,
+		Slug:       it.Slug,
+		CatalogID:  it.CatalogID,
+		Version:    it.Version,
+		CatVer:     it.CatVer,
+		Checksum:   it.Checksum,
 		Tags:       it.Tags,
 		Deprecated: boolMeta(it.Metadata, "deprecated"),
 		Changelog:  strMeta(it.Metadata, "changelog"),
-		Content:    content,
+		Content:    it.Content,
 		Metadata:   it.Metadata,
-		ApplyMode:  applyMode,
-		Globs:      globs,
+		ApplyMode:  it.ApplyMode,
+		Globs:      it.Globs,
+	}
+	if out.Slug == "" {
+		out.Slug = it.ID
+	}
+	normalizeCatalogItem(&out)
+	return out
+}
+
+// itemFromMetadata fills empty catalog fields from the metadata keys that
+// servers without FeatureCatalogFields use.
+func itemFromMetadata(it Item) Item {
+	fill := func(dst *string, keys ...string) {
+		for _, k := range keys {
+			if *dst != "" {
+				return
+			}
+			*dst = strMeta(it.Metadata, k)
+		}
+	}
+	fill(&it.Slug, "slug")
+	fill(&it.CatalogID, "catalogId")
+	if it.Version == "" {
+		it.Version = it.CatVer
 	}
+	fill(&it.Version, "catalogVersion", "version")
+	fill(&it.Checksum, "checksum")
+	fill(&it.Content, "content")
+	fill(&it.ApplyMode, "applyMode")
+	fill(&it.Globs, "globs")
+	return it
 }
 
 func normalizeCatalogItem(it *CatalogItem) {
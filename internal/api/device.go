@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Error codes returned by the device token endpoint while polling.
+const (
+	DeviceAuthorizationPending = "authorization_pending"
+	DeviceSlowDown             = "slow_down"
+	DeviceExpiredToken         = "expired_token"
+	DeviceAccessDenied         = "access_denied"
+)
+
+// DeviceCode is the server's answer to a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationURI         string `json:"verificationUri"`
+	VerificationURIComplete string `json:"verificationUriComplete,omitempty"`
+	ExpiresIn               int    `json:"expiresIn"`
+	Interval                int    `json:"interval"`
+}
+
+func (c *Client) DeviceAuthorize(ctx context.Context) (*DeviceCode, error) {
+	var out DeviceCode
+	if err := c.do(ctx, http.MethodPost, "/api/auth/device/code", "", map[string]any{}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeviceToken polls for the token of an approved device code. Until the user
+// approves, it returns an *APIError whose Code is one of the Device*
+// constants.
+func (c *Client) DeviceToken(ctx context.Context, deviceCode string) (*CLIAuthCallbackPayload, error) {
+	var out CLIAuthCallbackPayload
+	if err := c.do(ctx, http.MethodPost, "/api/auth/device/token", "", map[string]any{"deviceCode": deviceCode}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
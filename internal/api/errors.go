@@ -1,50 +1,118 @@
 package api
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 type ErrorEnvelope struct {
 	Error ErrorBody `json:"error"`
 }
 
 type ErrorBody struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
+// Sentinels matched by errors.Is against any *APIError with the
+// corresponding status, however deeply it is wrapped.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
 type APIError struct {
 	Status  int
 	Code    string
 	Message string
+	// RequestID is the server's request or correlation ID, for support.
+	RequestID string
+	// RetryAfter is the server's requested wait, if it sent one.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
-	if e.Code == "" {
-		return fmt.Sprintf("api error (%d): %s", e.Status, e.Message)
+	msg := fmt.Sprintf("api error (%d): %s", e.Status, e.Message)
+	if e.Code != "" {
+		msg = fmt.Sprintf("api error (%d/%s): %s", e.Status, e.Code, e.Message)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter.Round(time.Second))
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" [request id: %s]", e.RequestID)
 	}
-	return fmt.Sprintf("api error (%d/%s): %s", e.Status, e.Code, e.Message)
+	return msg
 }
 
-func ExitCode(err error) int {
-	if err == nil {
-		return 0
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrValidation:
+		return e.Status == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrServer:
+		return e.Status >= 500
 	}
-	ae, ok := err.(*APIError)
-	if !ok {
-		return 1
+	return false
+}
+
+func IsUnauthorized(err error) bool { return errors.Is(err, ErrUnauthorized) }
+func IsForbidden(err error) bool    { return errors.Is(err, ErrForbidden) }
+func IsNotFound(err error) bool     { return errors.Is(err, ErrNotFound) }
+func IsRateLimited(err error) bool  { return errors.Is(err, ErrRateLimited) }
+func IsServerError(err error) bool  { return errors.Is(err, ErrServer) }
+
+// RequestID returns the server request ID carried by err, if any.
+func RequestID(err error) string {
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.RequestID
 	}
-	switch ae.Status {
-	case 401:
+	return ""
+}
+
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "Request-Id", "X-Amzn-Requestid"}
+
+func requestIDFrom(h http.Header) string {
+	for _, k := range requestIDHeaders {
+		if v := h.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrOffline):
+		return 15
+	case errors.Is(err, ErrUnauthorized):
 		return 10
-	case 403:
+	case errors.Is(err, ErrForbidden):
 		return 11
-	case 422:
+	case errors.Is(err, ErrValidation):
 		return 12
-	case 429:
+	case errors.Is(err, ErrRateLimited):
 		return 13
+	case errors.Is(err, ErrServer):
+		return 14
 	default:
-		if ae.Status >= 500 {
-			return 14
-		}
 		return 1
 	}
 }
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExitCodeUnwrapsErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		want   int
+	}{
+		{http.StatusUnauthorized, 10},
+		{http.StatusForbidden, 11},
+		{http.StatusUnprocessableEntity, 12},
+		{http.StatusTooManyRequests, 13},
+		{http.StatusBadGateway, 14},
+		{http.StatusNotFound, 1},
+	}
+	for _, tc := range cases {
+		err := fmt.Errorf("token verification failed: %w", &APIError{Status: tc.status, Message: "x"})
+		if got := ExitCode(err); got != tc.want {
+			t.Fatalf("status %d: exit code %d, want %d", tc.status, got, tc.want)
+		}
+	}
+	if !IsNotFound(fmt.Errorf("wrapped: %w", &APIError{Status: 404})) || IsUnauthorized(&APIError{Status: 404}) {
+		t.Fatalf("classification helpers disagree with status")
+	}
+}
+
+func TestAPIErrorCarriesRequestID(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusNotFound, `{"error":{"code":"item_not_found","message":"no such item"}}`, http.Header{"X-Request-Id": {"req-42"}}), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	_, err := c.CatalogGetByRef(context.Background(), "tok", "rule", "missing")
+	if !IsNotFound(err) || RequestID(err) != "req-42" {
+		t.Fatalf("unexpected error %v (request id %q)", err, RequestID(err))
+	}
+	if !strings.Contains(err.Error(), "request id: req-42") {
+		t.Fatalf("request id missing from message: %v", err)
+	}
+}
+
+func TestErrorBodyShapes(t *testing.T) {
+	for _, tc := range []struct {
+		body, code, msg string
+	}{
+		{`{"error":{"code":"slow_down","message":"poll less often"}}`, "slow_down", "poll less often"},
+		{`{"error":{"code":"authorization_pending"}}`, "authorization_pending", "authorization_pending"},
+		{`{"error":"authorization_pending"}`, "authorization_pending", "authorization_pending"},
+		{`{"error":"slow_down","error_description":"poll less often"}`, "slow_down", "poll less often"},
+		{`{"error":"upgrade required"}`, "", "upgrade required"},
+	} {
+		aerr := parseErrorBody(http.StatusBadRequest, []byte(tc.body))
+		if aerr.Code != tc.code || aerr.Message != tc.msg {
+			t.Fatalf("%s: code=%q message=%q, want %q %q", tc.body, aerr.Code, aerr.Message, tc.code, tc.msg)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package api
 
-import "net/http"
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
 
 type uaRoundTripper struct {
 	next      http.RoundTripper
@@ -11,3 +19,67 @@ func (u uaRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	r.Header.Set("User-Agent", u.userAgent)
 	return u.next.RoundTrip(r)
 }
+
+const debugBodyLimit = 2048
+
+var fallbackRedactRe = regexp.MustCompile(`(?i)(bearer\s+|\b(?:\w*token|code|code_?verifier|device_?code)=|"(?:\w*token|code|code_?verifier|device_?code)"\s*:\s*")[^\s&"]+`)
+
+// fallbackRedact is used when ClientOptions.Redact is not set.
+func fallbackRedact(s string) string {
+	return fallbackRedactRe.ReplaceAllString(s, "${1}[REDACTED]")
+}
+
+// debugRoundTripper traces each HTTP exchange. Everything it prints goes
+// through redact first, so tokens never reach the log.
+type debugRoundTripper struct {
+	next   http.RoundTripper
+	out    io.Writer
+	redact func(string) string
+}
+
+func (d debugRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	d.logf("--> %s %s", r.Method, r.URL.String())
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		d.logf("    Authorization: %s", auth)
+	}
+	if r.Body != nil && r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, debugBodyLimit+1))
+			_ = body.Close()
+			d.logBody(b)
+		}
+	}
+	start := time.Now()
+	resp, err := d.next.RoundTrip(r)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		d.logf("<-- %s %s error after %s: %v", r.Method, r.URL.String(), elapsed, err)
+		return nil, err
+	}
+	d.logf("<-- %d %s %s (%s)", resp.StatusCode, r.Method, r.URL.String(), elapsed)
+	if resp.Body != nil {
+		b, rerr := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(b))
+		if rerr != nil {
+			return nil, rerr
+		}
+		d.logBody(b)
+	}
+	return resp, nil
+}
+
+func (d debugRoundTripper) logBody(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	s := string(b)
+	if len(b) > debugBodyLimit {
+		s = string(b[:debugBodyLimit]) + fmt.Sprintf("... (%d more bytes)", len(b)-debugBodyLimit)
+	}
+	d.logf("    %s", strings.TrimSpace(s))
+}
+
+func (d debugRoundTripper) logf(format string, args ...any) {
+	_, _ = fmt.Fprintln(d.out, "[debug] "+d.redact(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDebugTraceRedactsTokens(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `{"user":{"id":"u1","email":"dev@example.com"},"token":"server-secret"}`, nil), nil
+	})
+	var buf bytes.Buffer
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Debug: true, DebugOutput: &buf})
+	if _, err := c.AuthMe(context.Background(), "client-secret"); err != nil {
+		t.Fatalf("AuthMe error: %v", err)
+	}
+	log := buf.String()
+	if !strings.Contains(log, "--> GET https://example.com/api/auth/me") || !strings.Contains(log, "<-- 200") {
+		t.Fatalf("missing request trace:\n%s", log)
+	}
+	if strings.Contains(log, "client-secret") || strings.Contains(log, "server-secret") {
+		t.Fatalf("token leaked into debug log:\n%s", log)
+	}
+}
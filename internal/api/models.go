@@ -94,6 +94,10 @@ type CatalogSyncResult struct {
 	Deprecated     bool        `json:"deprecated"`
 	Removed        bool        `json:"removed"`
 	LatestItem     CatalogItem `json:"latestItem"`
+	// Failed is set when the item's batch could not be checked; Error holds
+	// the reason.
+	Failed bool   `json:"failed,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 type CatalogSyncResponse struct {
@@ -116,8 +120,14 @@ type OrgListResponse struct {
 }
 
 type CLIAuthCallbackPayload struct {
-	Token     string `json:"token"`
-	ExpiresAt string `json:"expiresAt,omitempty"`
+	Token     string   `json:"token"`
+	ExpiresAt string   `json:"expiresAt,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	// RefreshToken, when issued, renews Token without a browser login.
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// Code is a one-time authorization code to exchange for the token.
+	Code  string `json:"code,omitempty"`
+	State string `json:"state,omitempty"`
 }
 
 type InstallRecord struct {
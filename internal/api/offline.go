@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/httpcache"
+)
+
+// ErrOffline is returned when offline mode cannot serve a request from the
+// local cache.
+var ErrOffline = errors.New("not available offline")
+
+func (c *Client) Offline() bool {
+	return c.offline
+}
+
+// catalogBucket scopes remembered items to the profile, base URL, account
+// and org they were fetched from.
+func (c *Client) catalogBucket() string {
+	return "catalog-" + httpcache.Key(c.scope()...)[:16]
+}
+
+// scope identifies whose catalog the client reads.
+func (c *Client) scope() []string {
+	scope := []string{c.profile, c.baseURL, "user:" + c.identity}
+	if c.org != "" {
+		scope = append(scope, "org:"+c.org)
+	}
+	return scope
+}
+
+// remember records fetched catalog items so offline mode can serve them
+// later. Content from an earlier fetch of the same version is preserved when
+// a lighter response (for example search results) omits it.
+func (c *Client) remember(items ...CatalogItem) {
+	if c.cache == nil || c.offline {
+		return
+	}
+	bucket := c.catalogBucket()
+	for _, it := range items {
+		if it.CatalogID == "" {
+			continue
+		}
+		if it.Content == "" {
+			var prev CatalogItem
+			if _, ok := c.cache.GetObject(bucket, it.CatalogID, &prev); ok && prev.Version == it.Version {
+				it.Content = prev.Content
+			}
+		}
+		if err := c.cache.PutObject(bucket, it.CatalogID, it); err != nil {
+			c.debugf("offline index: skip %s: %v", it.CatalogID, err)
+		}
+	}
+}
+
+func (c *Client) offlineItems() ([]CatalogItem, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("%w: local cache is disabled", ErrOffline)
+	}
+	out := make([]CatalogItem, 0)
+	err := c.cache.EachObject(c.catalogBucket(), func(raw json.RawMessage, _ time.Time) error {
+		var it CatalogItem
+		if err := json.Unmarshal(raw, &it); err == nil {
+			out = append(out, it)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].CatalogID < out[j].CatalogID })
+	return out, err
+}
+
+func (c *Client) offlineGetByRef(itemType, slug string) (*CatalogItem, error) {
+	items, err := c.offlineItems()
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Type == itemType && items[i].Slug == slug {
+			if items[i].Content == "" {
+				return nil, fmt.Errorf("%w: content for @%s/%s was never downloaded", ErrOffline, itemType, slug)
+			}
+			return &items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: @%s/%s is not in the local cache", ErrOffline, itemType, slug)
+}
+
+func (c *Client) offlineSuggest(req CatalogLookupRequest) ([]CatalogItem, error) {
+	items, err := c.offlineItems()
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(strings.TrimSpace(req.Q))
+	tags := make([]string, 0, len(req.Tags))
+	for _, t := range req.Tags {
+		tags = append(tags, strings.ToLower(t))
+	}
+	type match struct {
+		item  CatalogItem
+		score int
+	}
+	matches := make([]match, 0)
+	for _, it := range items {
+		if req.Type != "" && it.Type != req.Type {
+			continue
+		}
+		score := offlineScore(it, query, tags)
+		if score == 0 && (query != "" || len(tags) > 0) {
+			continue
+		}
+		matches = append(matches, match{it, score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	out := make([]CatalogItem, len(matches))
+	for i, m := range matches {
+		out[i] = m.item
+	}
+	return out, nil
+}
+
+// offlineScore ranks a cached item against a query and tags the way the
+// search endpoint does: tag matches, query words naming a tag, and the query
+// appearing in the name or slug. Zero means no match.
+func offlineScore(it CatalogItem, query string, tags []string) int {
+	score := 0
+	for _, t := range it.Tags {
+		lt := strings.ToLower(t)
+		for _, want := range tags {
+			if lt == want {
+				score += 10
+			}
+		}
+		for _, word := range strings.Fields(query) {
+			if lt == word {
+				score += 5
+			}
+		}
+	}
+	if query != "" && (strings.Contains(strings.ToLower(it.Name), query) || strings.Contains(strings.ToLower(it.Title), query) || strings.Contains(it.Slug, query)) {
+		score += 20
+	}
+	return score
+}
+
+func (c *Client) offlineSync(req CatalogSyncRequest) (*CatalogSyncResponse, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("%w: local cache is disabled", ErrOffline)
+	}
+	bucket := c.catalogBucket()
+	results := make([]CatalogSyncResult, 0, len(req.Items))
+	missing := 0
+	for _, local := range req.Items {
+		var remote CatalogItem
+		if _, ok := c.cache.GetObject(bucket, local.CatalogID, &remote); !ok {
+			missing++
+			results = append(results, failedSyncResult(local, fmt.Errorf("%w: never fetched", ErrOffline)))
+			continue
+		}
+		// Search results carry no content; installing such an entry would
+		// overwrite the file with a placeholder.
+		if remote.Content == "" && remote.Version != local.Version {
+			results = append(results, failedSyncResult(local, fmt.Errorf("%w: content for %s was never downloaded", ErrOffline, remote.Version)))
+			continue
+		}
+		results = append(results, syncResult(local, &remote))
+	}
+	if missing > 0 && missing == len(req.Items) {
+		return nil, fmt.Errorf("%w: none of the %d installed item(s) have been fetched before", ErrOffline, len(req.Items))
+	}
+	return &CatalogSyncResponse{Results: results}, nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codemint/codemint-cli/internal/httpcache"
+)
+
+func TestOfflineServesPreviouslyFetchedItems(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `{"type":"rule","slug":"safe-api","catalogId":"rule:safe-api","version":"1.2.0","tags":["go"],"content":"# Safe API"}`, nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	online := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, Profile: "default", Identity: "email:dev@example.com"})
+	if _, err := online.CatalogGetByRef(context.Background(), "tok", "rule", "safe-api"); err != nil {
+		t.Fatalf("online resolve: %v", err)
+	}
+
+	offlineTransport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatalf("offline client made a request to %s", r.URL)
+		return nil, nil
+	})
+	offline := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: offlineTransport, Cache: store, Profile: "default", Identity: "email:dev@example.com", Offline: true})
+	item, err := offline.CatalogGetByRef(context.Background(), "", "rule", "safe-api")
+	if err != nil || item.Content != "# Safe API" {
+		t.Fatalf("offline resolve: item=%+v err=%v", item, err)
+	}
+	recs, err := offline.CatalogSuggest(context.Background(), "", CatalogLookupRequest{Type: "rule", Tags: []string{"go"}})
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("offline suggest: %+v err=%v", recs, err)
+	}
+	for q, want := range map[string]int{"safe": 1, "go": 1, "kotlin": 0} {
+		recs, err := offline.CatalogSuggest(context.Background(), "", CatalogLookupRequest{Q: q})
+		if err != nil || len(recs) != want {
+			t.Fatalf("offline suggest q=%q: %+v err=%v, want %d", q, recs, err, want)
+		}
+	}
+	resp, err := offline.CatalogSync(context.Background(), "", CatalogSyncRequest{Items: []CatalogSyncItem{{CatalogID: "rule:safe-api", Version: "1.0.0"}}})
+	if err != nil || resp.Results[0].LatestVersion != "1.2.0" {
+		t.Fatalf("offline sync: %+v err=%v", resp, err)
+	}
+
+	_, err = offline.AuthMe(context.Background(), "")
+	if !errors.Is(err, ErrOffline) || ExitCode(err) != 15 {
+		t.Fatalf("expected offline error, got %v", err)
+	}
+	_, err = offline.CatalogGetByRef(context.Background(), "", "rule", "unknown")
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected offline error for unknown item, got %v", err)
+	}
+}
+
+func TestOfflineSyncNeedsDownloadedContent(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `{"data":[{"id":"i1","name":"Safe API","type":"rule","slug":"safe-api","catalogId":"rule:safe-api","version":"1.2.0"}],"page":1,"limit":50,"total":1}`, nil), nil
+	})
+	store := httpcache.New(t.TempDir())
+	online := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Cache: store, Profile: "default", Identity: "email:dev@example.com"})
+	if _, err := online.CatalogSuggest(context.Background(), "tok", CatalogLookupRequest{Q: "safe"}); err != nil {
+		t.Fatalf("online suggest: %v", err)
+	}
+
+	offline := NewClient(ClientOptions{BaseURL: "https://example.com", Cache: store, Profile: "default", Identity: "email:dev@example.com", Offline: true})
+	resp, err := offline.CatalogSync(context.Background(), "", CatalogSyncRequest{Items: []CatalogSyncItem{
+		{CatalogID: "rule:safe-api", Version: "1.0.0"},
+	}})
+	if err != nil || !resp.Results[0].Failed || !strings.Contains(resp.Results[0].Error, "never downloaded") {
+		t.Fatalf("upgrade without content: %+v err=%v", resp, err)
+	}
+	resp, err = offline.CatalogSync(context.Background(), "", CatalogSyncRequest{Items: []CatalogSyncItem{
+		{CatalogID: "rule:safe-api", Version: "1.2.0"},
+	}})
+	if err != nil || resp.Results[0].Failed || resp.Results[0].LatestVersion != "1.2.0" {
+		t.Fatalf("up-to-date item without content: %+v err=%v", resp, err)
+	}
+}
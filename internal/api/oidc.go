@@ -0,0 +1,21 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// OIDCExchangeRequest carries a CI provider's OIDC ID token to be traded for
+// a short-lived CLI token.
+type OIDCExchangeRequest struct {
+	IDToken  string `json:"idToken"`
+	Provider string `json:"provider,omitempty"`
+}
+
+func (c *Client) ExchangeOIDCToken(ctx context.Context, req OIDCExchangeRequest) (*CLIAuthCallbackPayload, error) {
+	var out CLIAuthCallbackPayload
+	if err := c.do(ctx, http.MethodPost, "/api/auth/oidc/exchange", "", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
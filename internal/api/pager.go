@@ -0,0 +1,100 @@
+package api
+
+import "context"
+
+// maxSearchPages guards against servers that never report a final page.
+const maxSearchPages = 1000
+
+// SearchIterator walks every page of an items search, following the Total and
+// Limit reported by the server.
+//
+//	it := client.SearchAll(token, req, 0)
+//	for it.Next(ctx) {
+//		use(it.Item())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type SearchIterator struct {
+	c          *Client
+	token      string
+	req        ItemsSearchRequest
+	maxResults int
+
+	buf   []Item
+	idx   int
+	cur   Item
+	seen  int
+	total int
+	pages int
+	done  bool
+	err   error
+}
+
+// SearchAll returns an iterator over all matching items. maxResults caps the
+// number of items returned; 0 means no cap.
+func (c *Client) SearchAll(token string, req ItemsSearchRequest, maxResults int) *SearchIterator {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	return &SearchIterator{c: c, token: token, req: req, maxResults: maxResults}
+}
+
+func (it *SearchIterator) Next(ctx context.Context) bool {
+	if it.err != nil || (it.maxResults > 0 && it.seen >= it.maxResults) {
+		return false
+	}
+	if it.idx >= len(it.buf) {
+		if it.done || !it.fetch(ctx) {
+			return false
+		}
+	}
+	it.cur = it.buf[it.idx]
+	it.idx++
+	it.seen++
+	return true
+}
+
+func (it *SearchIterator) fetch(ctx context.Context) bool {
+	if it.pages >= maxSearchPages {
+		it.done = true
+		return false
+	}
+	resp, err := it.c.ItemsSearch(ctx, it.token, it.req)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.pages++
+	it.buf, it.idx = resp.Data, 0
+	if resp.Total > 0 {
+		it.total = resp.Total
+	}
+	limit := resp.Limit
+	if limit <= 0 {
+		limit = it.req.Limit
+	}
+	page := resp.Page
+	if page <= 0 {
+		page = it.req.Page
+	}
+	if len(resp.Data) == 0 || len(resp.Data) < limit || (resp.Total > 0 && page*limit >= resp.Total) {
+		it.done = true
+	}
+	it.req.Page = page + 1
+	return len(it.buf) > 0
+}
+
+func (it *SearchIterator) Item() Item {
+	return it.cur
+}
+
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Total is the server-reported number of matches, known after the first page.
+func (it *SearchIterator) Total() int {
+	return it.total
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSearchAllFollowsPages(t *testing.T) {
+	const total = 7
+	pages := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		pages++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		items := make([]string, 0, limit)
+		for i := (page - 1) * limit; i < page*limit && i < total; i++ {
+			items = append(items, fmt.Sprintf(`{"id":"i%d","type":"rule"}`, i))
+		}
+		body := fmt.Sprintf(`{"data":[%s],"page":%d,"limit":%d,"total":%d}`, strings.Join(items, ","), page, limit, total)
+		return jsonResponse(http.StatusOK, body, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+
+	it := c.SearchAll("tok", ItemsSearchRequest{Q: "x", Limit: 3}, 0)
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Item().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterator error: %v", err)
+	}
+	if len(ids) != total || ids[total-1] != "i6" || pages != 3 || it.Total() != total {
+		t.Fatalf("unexpected ids=%v pages=%d total=%d", ids, pages, it.Total())
+	}
+
+	pages = 0
+	it = c.SearchAll("tok", ItemsSearchRequest{Q: "x", Limit: 3}, 4)
+	n := 0
+	for it.Next(context.Background()) {
+		n++
+	}
+	if n != 4 || pages != 2 {
+		t.Fatalf("max results: got %d items over %d pages", n, pages)
+	}
+}
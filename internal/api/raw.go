@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type RawRequest struct {
+	Method string
+	Path   string
+	Token  string
+	// Body is sent verbatim as JSON when non-empty.
+	Body []byte
+}
+
+type RawResponse struct {
+	Status int
+	Proto  string
+	Header http.Header
+	Body   []byte
+}
+
+// Raw calls an arbitrary endpoint with the client's auth, retry and error
+// handling, returning the undecoded response.
+func (c *Client) Raw(ctx context.Context, req RawRequest) (*RawResponse, error) {
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	path := req.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	var out RawResponse
+	if err := c.send(ctx, request{method: method, path: path, token: req.Token, body: req.Body, capture: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
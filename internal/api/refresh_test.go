@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUnauthorizedRefreshesOnceAndReplays(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			return jsonResponse(http.StatusUnauthorized, `{"error":"expired"}`, nil), nil
+		}
+		return jsonResponse(http.StatusOK, `{"user":{"id":"u1","email":"dev@example.com"}}`, nil), nil
+	})
+	refreshes := 0
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Refresh: func(_ context.Context, stale string) (string, error) {
+		refreshes++
+		if stale != "stale" {
+			t.Fatalf("refresh got %q", stale)
+		}
+		return "fresh", nil
+	}})
+	me, err := c.AuthMe(context.Background(), "stale")
+	if err != nil || me.ID != "u1" || refreshes != 1 {
+		t.Fatalf("AuthMe = %+v, %v after %d refresh(es)", me, err, refreshes)
+	}
+}
+
+func TestFailedRefreshReturnsOriginalUnauthorized(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(http.StatusUnauthorized, `{"error":"expired"}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Refresh: func(context.Context, string) (string, error) {
+		return "", errors.New("refresh token revoked")
+	}})
+	_, err := c.AuthMe(context.Background(), "stale")
+	if !IsUnauthorized(err) || calls != 1 {
+		t.Fatalf("expected one unauthorized call, got %d calls, %v", calls, err)
+	}
+	if !strings.Contains(err.Error(), "codemint auth login") {
+		t.Fatalf("expected login hint in %v", err)
+	}
+}
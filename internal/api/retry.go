@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the client retries failed requests. Zero fields
+// fall back to DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Budget      time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Budget:      30 * time.Second,
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.Budget <= 0 {
+		p.Budget = def.Budget
+	}
+	return p
+}
+
+// backoff returns the full-jitter exponential delay for the given zero-based
+// retry number.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.BaseDelay << uint(retry)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+var now = time.Now
+
+// retryAfter extracts the server's requested wait from Retry-After or the
+// X-RateLimit-* headers. It reports false when no hint is present.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			if secs < 0 {
+				secs = 0
+			}
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return nonNegative(at.Sub(now())), true
+		}
+	}
+	if strings.TrimSpace(h.Get("X-RateLimit-Remaining")) != "0" {
+		return 0, false
+	}
+	reset := strings.TrimSpace(h.Get("X-RateLimit-Reset"))
+	if reset == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(reset, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	// Servers disagree on whether the reset is an epoch timestamp or a delta;
+	// anything past 2001 is treated as a timestamp.
+	if n > 1_000_000_000 {
+		return nonNegative(time.Unix(n, 0).Sub(now())), true
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func transient(err error) bool {
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return nerr.Timeout()
+	}
+	return false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
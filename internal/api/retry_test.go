@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func jsonResponse(status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestRetryAfter(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	defer func() { now = time.Now }()
+
+	cases := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second, true},
+		{"http date", http.Header{"Retry-After": {fixed.Add(7 * time.Second).Format(http.TimeFormat)}}, 7 * time.Second, true},
+		{"past date", http.Header{"Retry-After": {fixed.Add(-time.Minute).Format(http.TimeFormat)}}, 0, true},
+		{"ratelimit epoch", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1767323105"}}, 60 * time.Second, true},
+		{"ratelimit delta", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"5"}}, 5 * time.Second, true},
+		{"ratelimit remaining", http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {"5"}}, 0, false},
+		{"none", http.Header{}, 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := retryAfter(tc.header)
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("%s: got (%s,%v) want (%s,%v)", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRetriesRateLimitedGet(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return jsonResponse(http.StatusTooManyRequests, `{"error":"slow down"}`, http.Header{"Retry-After": {"0"}}), nil
+		}
+		return jsonResponse(http.StatusOK, `{"user":{"id":"u1","email":"dev@example.com"}}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	me, err := c.AuthMe(context.Background(), "tok")
+	if err != nil {
+		t.Fatalf("AuthMe error: %v", err)
+	}
+	if calls != 2 || me.ID != "u1" {
+		t.Fatalf("unexpected calls=%d me=%+v", calls, me)
+	}
+}
+
+func TestDoesNotRetryNonIdempotentPost(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(http.StatusServiceUnavailable, `{"error":"down"}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Retry: RetryPolicy{BaseDelay: time.Millisecond}})
+	err := c.do(context.Background(), http.MethodPost, "/api/anything", "tok", map[string]any{}, nil)
+	if ExitCode(err) != 14 {
+		t.Fatalf("expected 5xx exit code, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("POST retried %d times", calls-1)
+	}
+}
+
+func TestRetryStopsAtBudget(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(http.StatusTooManyRequests, `{"error":"limited"}`, http.Header{"Retry-After": {"120"}}), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, Retry: RetryPolicy{Budget: time.Second}})
+	_, err := c.AuthMe(context.Background(), "tok")
+	if ExitCode(err) != 13 {
+		t.Fatalf("expected rate limit exit code, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no retry beyond budget, got %d calls", calls)
+	}
+}
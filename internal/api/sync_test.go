@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCatalogSyncReportsFailedBatches(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var in struct {
+			CatalogIDs []string `json:"catalogIds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if in.CatalogIDs[0] == "rule:item-100" {
+			return jsonResponse(http.StatusBadRequest, `{"error":"bad batch"}`, nil), nil
+		}
+		items := make([]string, 0, len(in.CatalogIDs))
+		for _, id := range in.CatalogIDs {
+			items = append(items, fmt.Sprintf(`{"catalogId":%q,"type":"rule","slug":%q,"version":"2.0.0"}`, id, strings.TrimPrefix(id, "rule:")))
+		}
+		return jsonResponse(http.StatusOK, `{"items":[`+strings.Join(items, ",")+`]}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport, SyncConcurrency: 3})
+
+	req := CatalogSyncRequest{}
+	for i := 0; i < 250; i++ {
+		req.Items = append(req.Items, CatalogSyncItem{CatalogID: fmt.Sprintf("rule:item-%d", i), Version: "1.0.0"})
+	}
+	resp, err := c.CatalogSync(context.Background(), "tok", req)
+	if err != nil {
+		t.Fatalf("CatalogSync error: %v", err)
+	}
+	if len(resp.Results) != len(req.Items) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(req.Items))
+	}
+	for i, res := range resp.Results {
+		if res.CatalogID != req.Items[i].CatalogID {
+			t.Fatalf("result %d out of order: %s", i, res.CatalogID)
+		}
+		wantFailed := i >= 100 && i < 200
+		if res.Failed != wantFailed {
+			t.Fatalf("result %d failed=%v want %v", i, res.Failed, wantFailed)
+		}
+		if !wantFailed && res.LatestVersion != "2.0.0" {
+			t.Fatalf("result %d unexpected version %q", i, res.LatestVersion)
+		}
+	}
+}
+
+func TestCatalogSyncReturnsErrorWhenEveryBatchFails(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusUnauthorized, `{"error":"expired"}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	_, err := c.CatalogSync(context.Background(), "tok", CatalogSyncRequest{Items: []CatalogSyncItem{{CatalogID: "rule:a"}}})
+	if ExitCode(err) != 10 {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestCatalogSyncReportsSingleBatchFailurePerItem(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusBadRequest, `{"error":"bad batch"}`, nil), nil
+	})
+	c := NewClient(ClientOptions{BaseURL: "https://example.com", Transport: transport})
+	resp, err := c.CatalogSync(context.Background(), "tok", CatalogSyncRequest{Items: []CatalogSyncItem{{CatalogID: "rule:a"}, {CatalogID: "rule:b"}}})
+	if err != nil {
+		t.Fatalf("CatalogSync error: %v", err)
+	}
+	for _, res := range resp.Results {
+		if !res.Failed || res.Error == "" {
+			t.Fatalf("expected a per-item failure, got %+v", res)
+		}
+	}
+}
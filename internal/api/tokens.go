@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CLIToken describes one CLI token issued to the current user. Current marks
+// the token that made the request.
+type CLIToken struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	LastUsedAt string `json:"lastUsedAt,omitempty"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	Current    bool   `json:"current"`
+}
+
+type CLITokenListResponse struct {
+	Tokens []CLIToken `json:"tokens"`
+}
+
+func (c *Client) ListCLITokens(ctx context.Context, token string) (*CLITokenListResponse, error) {
+	var out CLITokenListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/auth/cli-token", token, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeCLIToken invalidates the token with the given ID.
+func (c *Client) RevokeCLIToken(ctx context.Context, token, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/auth/cli-token/"+url.PathEscape(id), token, nil, nil)
+}
+
+// RevokeCurrentCLIToken invalidates the token that makes the request. A 401
+// is returned as is: refreshing first would mint a new token pair only to
+// revoke its access token.
+func (c *Client) RevokeCurrentCLIToken(ctx context.Context, token string) error {
+	return c.send(ctx, request{method: http.MethodDelete, path: "/api/auth/cli-token", token: token, refreshed: true})
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures certificate trust and client authentication for
+// enterprise gateways.
+type TLSOptions struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	MinVersion         string
+	InsecureSkipVerify bool
+	// Pins are base64 SHA-256 hashes of the server's public key (SPKI). When
+	// set, the leaf certificate must match one of them.
+	Pins []string
+}
+
+type TransportOptions struct {
+	TLS TLSOptions
+	// Proxy is an http(s) or socks5 proxy URL. When empty the standard
+	// HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables apply.
+	Proxy string
+}
+
+// NewTransport clones the default transport and applies the TLS and proxy
+// options.
+func NewTransport(opts TransportOptions) (http.RoundTripper, error) {
+	cfg, err := buildTLSConfig(opts.TLS)
+	if err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = cfg
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		tr.Proxy = http.ProxyURL(u)
+	}
+	return tr, nil
+}
+
+func buildTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{MinVersion: minVersion, InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if len(opts.Pins) > 0 {
+		pins := make(map[string]struct{}, len(opts.Pins))
+		for _, p := range opts.Pins {
+			pins[strings.TrimPrefix(strings.TrimSpace(p), "sha256/")] = struct{}{}
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("tls pin check: no server certificate")
+			}
+			leaf := cs.PeerCertificates[0]
+			if _, ok := pins[SPKIHash(leaf)]; !ok {
+				return fmt.Errorf("tls pin check: %s presented key sha256/%s, which is not pinned", cs.ServerName, SPKIHash(leaf))
+			}
+			return nil
+		}
+	}
+	return cfg, nil
+}
+
+// SPKIHash returns the base64 SHA-256 of the certificate's public key, the
+// format used by TLSOptions.Pins.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func parseTLSVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "tls") {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("TLS %s is no longer supported; use 1.2 or 1.3", v)
+	default:
+		return 0, fmt.Errorf("invalid minimum TLS version %q (use 1.2 or 1.3)", v)
+	}
+}
+
+// Handshake connects to the base URL and returns the negotiated TLS state.
+// It returns nil state and no error for plain-HTTP base URLs.
+func (c *Client) Handshake(ctx context.Context) (*tls.ConnectionState, error) {
+	if c.offline {
+		return nil, fmt.Errorf("%w: TLS handshake needs the network", ErrOffline)
+	}
+	if !strings.HasPrefix(c.baseURL, "https://") {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.TLS == nil {
+		return nil, fmt.Errorf("no TLS connection state for %s", c.baseURL)
+	}
+	return resp.TLS, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransportTrustsCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	untrusted, err := NewTransport(TransportOptions{})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	if _, err := NewClient(ClientOptions{BaseURL: srv.URL, Transport: untrusted, Retry: RetryPolicy{MaxAttempts: 1}}).Handshake(context.Background()); err == nil {
+		t.Fatalf("expected handshake to fail without the test CA")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := NewTransport(TransportOptions{TLS: TLSOptions{CAFile: caFile, MinVersion: "1.2"}})
+	if err != nil {
+		t.Fatalf("NewTransport with CA: %v", err)
+	}
+	state, err := NewClient(ClientOptions{BaseURL: srv.URL, Transport: trusted}).Handshake(context.Background())
+	if err != nil || state == nil || state.Version < tls.VersionTLS12 {
+		t.Fatalf("handshake with CA: state=%v err=%v", state, err)
+	}
+}
+
+func TestTransportEnforcesPins(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		pin string
+		ok  bool
+	}{
+		{"sha256/" + SPKIHash(srv.Certificate()), true},
+		{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", false},
+	} {
+		tr, err := NewTransport(TransportOptions{TLS: TLSOptions{InsecureSkipVerify: true, Pins: []string{tc.pin}}})
+		if err != nil {
+			t.Fatalf("NewTransport: %v", err)
+		}
+		_, err = NewClient(ClientOptions{BaseURL: srv.URL, Transport: tr}).Handshake(context.Background())
+		if (err == nil) != tc.ok {
+			t.Fatalf("pin %s: ok=%v err=%v", tc.pin, tc.ok, err)
+		}
+	}
+}
+
+func TestTransportRejectsInvalidOptions(t *testing.T) {
+	cases := []TLSOptions{
+		{MinVersion: "1.0"},
+		{MinVersion: "banana"},
+		{CertFile: "client.pem"},
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	}
+	for _, tc := range cases {
+		if _, err := NewTransport(TransportOptions{TLS: tc}); err == nil {
+			t.Fatalf("expected error for %+v", tc)
+		}
+	}
+}
+
+func TestTransportUsesConfiguredProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`{"user":{"id":"u1"}}`))
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	c := NewClient(ClientOptions{BaseURL: "http://codemint.internal", Transport: tr})
+	if _, err := c.AuthMe(context.Background(), "tok"); err != nil {
+		t.Fatalf("AuthMe via proxy: %v", err)
+	}
+	if proxied != "http://codemint.internal/api/auth/me" {
+		t.Fatalf("proxy saw %q", proxied)
+	}
+	if _, err := NewTransport(TransportOptions{Proxy: "not a url"}); err == nil {
+		t.Fatalf("expected invalid proxy URL to be rejected")
+	}
+}
@@ -2,27 +2,65 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"net"
 	"net/http"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/codemint/codemint-cli/internal/api"
 )
 
+// DefaultCallbackHost is where the login callback listens unless
+// --callback-host says otherwise.
+const DefaultCallbackHost = "127.0.0.1"
+
 type callbackServer struct {
-	server *http.Server
-	ln     net.Listener
-	tokens chan api.CLIAuthCallbackPayload
+	server  *http.Server
+	ln      net.Listener
+	host    string
+	state   string
+	results chan callbackResult
+}
+
+type callbackResult struct {
+	payload api.CLIAuthCallbackPayload
+	err     error
 }
 
-func newCallbackServer() (*callbackServer, error) {
-	ln, err := net.Listen("tcp", "127.0.0.1:0")
+// CallbackError is returned when the browser comes back with an OAuth-style
+// error instead of a code, for example because the user cancelled.
+type CallbackError struct {
+	Code        string
+	Description string
+}
+
+func (e *CallbackError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("login was not completed: %s (%s)", e.Description, e.Code)
+	}
+	return "login was not completed: " + e.Code
+}
+
+// newCallbackServer listens on host:port (a random port when port is 0) and
+// accepts only callbacks that echo state.
+func newCallbackServer(state, host string, port int) (*callbackServer, error) {
+	if host == "" {
+		host = DefaultCallbackHost
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return nil, fmt.Errorf("port %d on %s is already in use; pick another with --port", port, host)
+	}
 	if err != nil {
 		return nil, err
 	}
-	cs := &callbackServer{ln: ln, tokens: make(chan api.CLIAuthCallbackPayload, 1)}
+	cs := &callbackServer{ln: ln, host: host, state: state, results: make(chan callbackResult, 1)}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", cs.handle)
 	mux.HandleFunc("/callback", cs.handle)
@@ -37,12 +75,14 @@ func (s *callbackServer) Port() int {
 	return s.ln.Addr().(*net.TCPAddr).Port
 }
 
+// WaitForToken returns the first valid callback, or the error the browser
+// reported.
 func (s *callbackServer) WaitForToken(ctx context.Context) (api.CLIAuthCallbackPayload, error) {
 	select {
 	case <-ctx.Done():
 		return api.CLIAuthCallbackPayload{}, ctx.Err()
-	case tok := <-s.tokens:
-		return tok, nil
+	case res := <-s.results:
+		return res.payload, res.err
 	}
 }
 
@@ -50,18 +90,79 @@ func (s *callbackServer) Close(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// RedirectURI is the callback URL the browser is sent to. A wildcard listen
+// address is reached through a forwarded loopback port, so it maps to
+// 127.0.0.1.
+func (s *callbackServer) RedirectURI() string {
+	host := s.host
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = DefaultCallbackHost
+	}
+	return fmt.Sprintf("http://%s/callback", net.JoinHostPort(host, strconv.Itoa(s.Port())))
+}
+
 func (s *callbackServer) handle(w http.ResponseWriter, r *http.Request) {
-	payload := api.CLIAuthCallbackPayload{Token: r.URL.Query().Get("token"), ExpiresAt: r.URL.Query().Get("expiresAt")}
-	if payload.Token == "" && r.Method == http.MethodPost {
+	q := r.URL.Query()
+	payload := api.CLIAuthCallbackPayload{Token: q.Get("token"), ExpiresAt: q.Get("expiresAt"), Code: q.Get("code"), State: q.Get("state")}
+	if payload.Token == "" && payload.Code == "" && q.Get("error") == "" && r.Method == http.MethodPost {
 		_ = json.NewDecoder(r.Body).Decode(&payload)
 	}
-	if payload.Token == "" {
-		http.Error(w, "missing token", http.StatusBadRequest)
+	if payload.State == "" || subtle.ConstantTimeCompare([]byte(payload.State), []byte(s.state)) != 1 {
+		renderCallbackPage(w, http.StatusBadRequest, false, "This sign-in link is invalid or belongs to another login attempt. Run `codemint auth login` again.")
+		return
+	}
+	if code := q.Get("error"); code != "" {
+		cerr := &CallbackError{Code: code, Description: q.Get("error_description")}
+		s.deliver(callbackResult{err: cerr})
+		msg := cerr.Description
+		if msg == "" {
+			msg = "The sign-in was cancelled or denied (" + code + ")."
+		}
+		renderCallbackPage(w, http.StatusOK, false, msg)
+		return
+	}
+	if payload.Token == "" && payload.Code == "" {
+		renderCallbackPage(w, http.StatusBadRequest, false, "The sign-in response did not include a code.")
 		return
 	}
+	s.deliver(callbackResult{payload: payload})
+	renderCallbackPage(w, http.StatusOK, true, "You can close this tab and return to the terminal.")
+}
+
+func (s *callbackServer) deliver(res callbackResult) {
 	select {
-	case s.tokens <- payload:
+	case s.results <- res:
 	default:
 	}
-	_, _ = fmt.Fprintln(w, "CodeMint CLI authentication complete. You can close this tab.")
+}
+
+var callbackPage = template.Must(template.New("callback").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>CodeMint CLI</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;display:flex;align-items:center;justify-content:center;min-height:100vh;margin:0;background:#f6f8fa;color:#1f2328}
+main{background:#fff;border:1px solid #d0d7de;border-radius:8px;padding:2rem 2.5rem;max-width:32rem;text-align:center}
+h1{font-size:1.25rem;margin:0 0 .75rem;color:{{if .OK}}#1a7f37{{else}}#cf222e{{end}}}
+p{margin:0;line-height:1.5}
+</style>
+</head>
+<body>
+<main>
+<h1>{{if .OK}}CodeMint CLI authentication complete{{else}}CodeMint CLI sign-in failed{{end}}</h1>
+<p>{{.Message}}</p>
+</main>
+</body>
+</html>
+`))
+
+func renderCallbackPage(w http.ResponseWriter, status int, ok bool, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = callbackPage.Execute(w, struct {
+		OK      bool
+		Message string
+	}{ok, message})
 }
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+// ExpiryWarning is how close to expiry a token must be before commands warn.
+const ExpiryWarning = 72 * time.Hour
+
+// Credential is what the TokenStore holds: the token plus what we know about
+// it. Stores written by older versions contain only the bare token.
+type Credential struct {
+	Token        string     `json:"token"`
+	RefreshToken string     `json:"refreshToken,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	IssuedAt     *time.Time `json:"issuedAt,omitempty"`
+	BaseURL      string     `json:"baseUrl,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Scopes       []string   `json:"scopes,omitempty"`
+	// Replaced is the SHA-256 of the token this one replaced at refresh,
+	// so callers still holding it can pick up the new one.
+	Replaced string `json:"replaced,omitempty"`
+}
+
+// timeAt returns a pointer for the optional Credential timestamps.
+func timeAt(t time.Time) *time.Time {
+	return &t
+}
+
+// Expired reports whether the token has a known expiry that has passed.
+func (c Credential) Expired() bool {
+	return c.ExpiresAt != nil && !time.Now().Before(*c.ExpiresAt)
+}
+
+// ExpiresWithin reports whether a known expiry falls within d from now.
+func (c Credential) ExpiresWithin(d time.Duration) bool {
+	return c.ExpiresAt != nil && time.Until(*c.ExpiresAt) < d
+}
+
+// ExpiredError is returned instead of calling the server with a token that
+// is known to have expired. It matches api.ErrUnauthorized.
+type ExpiredError struct {
+	ExpiresAt time.Time
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("your CodeMint token expired on %s; run `codemint auth login`", e.ExpiresAt.Local().Format("2006-01-02 15:04 MST"))
+}
+
+func (e *ExpiredError) Unwrap() error {
+	return api.ErrUnauthorized
+}
+
+func SaveCredential(ctx context.Context, store TokenStore, cred Credential) error {
+	b, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, string(b))
+}
+
+// RevokeCredential invalidates cred's token and refresh token on the
+// server. A token the server already rejects needs no revoking.
+func RevokeCredential(ctx context.Context, client *api.Client, cred Credential) error {
+	if err := client.RevokeCurrentCLIToken(ctx, cred.Token); err != nil && !errors.Is(err, api.ErrUnauthorized) {
+		return err
+	}
+	if cred.RefreshToken == "" {
+		return nil
+	}
+	return client.RevokeRefreshToken(ctx, cred.RefreshToken)
+}
+
+// LoadCredential reads the stored credential, accepting bare-token entries
+// written before metadata was stored.
+func LoadCredential(ctx context.Context, store TokenStore) (Credential, error) {
+	raw, err := store.Get(ctx)
+	if err != nil {
+		return Credential{}, err
+	}
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "{") {
+		var cred Credential
+		if err := json.Unmarshal([]byte(raw), &cred); err == nil && cred.Token != "" {
+			// Earlier versions wrote unknown times as 0001-01-01.
+			if cred.ExpiresAt != nil && cred.ExpiresAt.IsZero() {
+				cred.ExpiresAt = nil
+			}
+			if cred.IssuedAt != nil && cred.IssuedAt.IsZero() {
+				cred.IssuedAt = nil
+			}
+			return cred, nil
+		}
+	}
+	if raw == "" {
+		return Credential{}, ErrNotLoggedIn
+	}
+	return Credential{Token: raw}, nil
+}
+
+// CheckExpiry returns an *ExpiredError for an expired credential and a
+// human-readable warning when it expires within ExpiryWarning.
+func CheckExpiry(cred Credential) (warning string, err error) {
+	if cred.Expired() {
+		return "", &ExpiredError{ExpiresAt: *cred.ExpiresAt}
+	}
+	if cred.ExpiresWithin(ExpiryWarning) {
+		left := time.Until(*cred.ExpiresAt).Round(time.Minute)
+		return fmt.Sprintf("your CodeMint token expires in %s (%s); run `codemint auth login` to renew it", left, cred.ExpiresAt.Local().Format("2006-01-02 15:04 MST")), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+func TestLoadCredentialAcceptsBareToken(t *testing.T) {
+	store := &memStore{token: "legacy-token\n"}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.Token != "legacy-token" || cred.ExpiresAt != nil {
+		t.Fatalf("LoadCredential = %+v, %v", cred, err)
+	}
+	if warning, err := CheckExpiry(cred); warning != "" || err != nil {
+		t.Fatalf("unknown expiry should not warn: %q, %v", warning, err)
+	}
+}
+
+func TestCredentialRoundTripAndExpiry(t *testing.T) {
+	store := &memStore{}
+	in := Credential{Token: "t", Email: "dev@example.com", ExpiresAt: timeAt(time.Now().Add(time.Hour).UTC()), Scopes: []string{"catalog:read"}}
+	if err := SaveCredential(context.Background(), store, in); err != nil {
+		t.Fatal(err)
+	}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.Token != "t" || cred.Email != in.Email || cred.ExpiresAt == nil || !cred.ExpiresAt.Equal(*in.ExpiresAt) {
+		t.Fatalf("LoadCredential = %+v, %v", cred, err)
+	}
+	if warning, err := CheckExpiry(cred); err != nil || !strings.Contains(warning, "expires in") {
+		t.Fatalf("expected a warning, got %q, %v", warning, err)
+	}
+
+	cred.ExpiresAt = timeAt(time.Now().Add(-time.Minute))
+	_, err = CheckExpiry(cred)
+	var expired *ExpiredError
+	if !errors.As(err, &expired) || !errors.Is(err, api.ErrUnauthorized) || api.ExitCode(err) != 10 {
+		t.Fatalf("expected an unauthorized ExpiredError, got %v", err)
+	}
+}
+
+func TestUnknownExpiryIsOmitted(t *testing.T) {
+	store := &memStore{}
+	if err := SaveCredential(context.Background(), store, Credential{Token: "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(store.token, "expiresAt") || strings.Contains(store.token, "issuedAt") {
+		t.Fatalf("unknown times were stored: %s", store.token)
+	}
+
+	store.token = `{"token":"t","expiresAt":"0001-01-01T00:00:00Z","issuedAt":"0001-01-01T00:00:00Z"}`
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.ExpiresAt != nil || cred.IssuedAt != nil || cred.Expired() {
+		t.Fatalf("zero times from older versions should read as unknown: %+v, %v", cred, err)
+	}
+}
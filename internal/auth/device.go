@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const (
+	defaultDeviceInterval = 5 * time.Second
+	slowDownStep          = 5 * time.Second
+)
+
+var errDeviceExpired = errors.New("device code expired before it was approved; run `codemint auth login --device` again")
+
+var pollWait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// DeviceLogin signs in without a local browser: the user approves a short
+// code on any other device while the CLI polls for the token.
+func DeviceLogin(ctx context.Context, opts LoginOptions) (*LoginResult, error) {
+	code, err := opts.Client.DeviceAuthorize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start device login: %w", err)
+	}
+	fmt.Printf("To sign in, open %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("Or open: %s\n", code.VerificationURIComplete)
+	}
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	waitCtx := ctx
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	for {
+		if err := pollWait(waitCtx, interval); err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				return nil, errDeviceExpired
+			}
+			return nil, err
+		}
+		payload, err := opts.Client.DeviceToken(waitCtx, code.DeviceCode)
+		if err == nil {
+			return finishLogin(ctx, opts, *payload)
+		}
+		var aerr *api.APIError
+		if !errors.As(err, &aerr) {
+			return nil, fmt.Errorf("device login: %w", err)
+		}
+		switch aerr.Code {
+		case api.DeviceAuthorizationPending:
+		case api.DeviceSlowDown:
+			interval += slowDownStep
+		case api.DeviceExpiredToken:
+			return nil, errDeviceExpired
+		case api.DeviceAccessDenied:
+			return nil, fmt.Errorf("device login was denied")
+		default:
+			return nil, fmt.Errorf("device login: %w", err)
+		}
+	}
+}
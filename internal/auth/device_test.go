@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+type memStore struct{ token string }
+
+func (m *memStore) Set(_ context.Context, token string) error { m.token = token; return nil }
+func (m *memStore) Get(_ context.Context) (string, error) {
+	if m.token == "" {
+		return "", ErrNotLoggedIn
+	}
+	return m.token, nil
+}
+func (m *memStore) Delete(_ context.Context) error { m.token = ""; return nil }
+
+// deviceServer answers the first polls with pollCodes, as nested CodeMint
+// errors or, with flat, as RFC 8628 {"error": "..."} bodies.
+func deviceServer(t *testing.T, flat bool, pollCodes ...string) *httptest.Server {
+	t.Helper()
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/device/code", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(api.DeviceCode{DeviceCode: "dc", UserCode: "ABCD-1234", VerificationURI: "https://example.com/device", Interval: 1, ExpiresIn: 600})
+	})
+	mux.HandleFunc("/api/auth/device/token", func(w http.ResponseWriter, _ *http.Request) {
+		if polls < len(pollCodes) {
+			code := pollCodes[polls]
+			polls++
+			w.WriteHeader(http.StatusBadRequest)
+			if flat {
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": "waiting for " + code})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.ErrorEnvelope{Error: api.ErrorBody{Code: code, Message: code}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.CLIAuthCallbackPayload{Token: "device-token"})
+	})
+	mux.HandleFunc("/api/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer device-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"user":{"id":"u1","email":"dev@example.com"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func stubPollWait(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	orig := pollWait
+	pollWait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { pollWait = orig })
+	return &waits
+}
+
+func TestDeviceLoginHonorsSlowDown(t *testing.T) {
+	for _, flat := range []bool{false, true} {
+		waits := stubPollWait(t)
+		srv := deviceServer(t, flat, api.DeviceAuthorizationPending, api.DeviceSlowDown, api.DeviceAuthorizationPending)
+		store := &memStore{}
+		client := api.NewClient(api.ClientOptions{BaseURL: srv.URL, Timeout: 2 * time.Second})
+
+		res, err := DeviceLogin(context.Background(), LoginOptions{Client: client, Store: store})
+		if err != nil {
+			t.Fatalf("flat=%v: DeviceLogin: %v", flat, err)
+		}
+		cred, err := LoadCredential(context.Background(), store)
+		if err != nil || res.Email != "dev@example.com" || cred.Token != "device-token" || cred.Email != "dev@example.com" {
+			t.Fatalf("flat=%v: unexpected result %+v, stored %+v, %v", flat, res, cred, err)
+		}
+		want := []time.Duration{time.Second, time.Second, 6 * time.Second, 6 * time.Second}
+		if len(*waits) != len(want) {
+			t.Fatalf("flat=%v: waits = %v, want %v", flat, *waits, want)
+		}
+		for i := range want {
+			if (*waits)[i] != want[i] {
+				t.Fatalf("flat=%v: waits = %v, want %v", flat, *waits, want)
+			}
+		}
+	}
+}
+
+func TestDeviceLoginExpired(t *testing.T) {
+	for _, flat := range []bool{false, true} {
+		stubPollWait(t)
+		srv := deviceServer(t, flat, api.DeviceAuthorizationPending, api.DeviceExpiredToken)
+		store := &memStore{}
+		client := api.NewClient(api.ClientOptions{BaseURL: srv.URL, Timeout: 2 * time.Second})
+
+		_, err := DeviceLogin(context.Background(), LoginOptions{Client: client, Store: store})
+		if !errors.Is(err, errDeviceExpired) {
+			t.Fatalf("flat=%v: expected expiry error, got %v", flat, err)
+		}
+		if store.token != "" {
+			t.Fatalf("flat=%v: nothing should be stored on failure", flat)
+		}
+	}
+}
+
+func TestDeviceLoginAccessDeniedFlat(t *testing.T) {
+	stubPollWait(t)
+	srv := deviceServer(t, true, api.DeviceAccessDenied)
+	client := api.NewClient(api.ClientOptions{BaseURL: srv.URL, Timeout: 2 * time.Second})
+	_, err := DeviceLogin(context.Background(), LoginOptions{Client: client, Store: &memStore{}})
+	if err == nil || err.Error() != "device login was denied" {
+		t.Fatalf("expected denial, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/util"
+)
+
+// EnvTokenPassphrase unlocks the encrypted token store without a prompt.
+const EnvTokenPassphrase = "CODEMINT_TOKEN_PASSPHRASE"
+
+const (
+	kdfPassphrase   = "pbkdf2-sha256"
+	kdfKeyFile      = "keyfile-hmac-sha256"
+	pbkdf2Iter      = 600000
+	sealedVersion   = 1
+	encryptedSuffix = ".enc"
+)
+
+// ErrNoTokenKey is returned when the encrypted store has neither a
+// passphrase nor a key file to unlock it.
+var ErrNoTokenKey = errors.New("encrypted token store is locked: set " + EnvTokenPassphrase + " or token_store.key_file")
+
+type sealedToken struct {
+	Version    int    `json:"v"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iter,omitempty"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ct"`
+}
+
+// encryptedStore seals the token with AES-256-GCM under a key derived from a
+// passphrase or key file. The profile name is bound in as associated data so
+// files cannot be swapped between profiles.
+type encryptedStore struct {
+	profile    string
+	path       string
+	keyFile    string
+	passphrase func() (string, error)
+	// legacy is the plaintext store this one replaces; it is read when no
+	// sealed file exists yet and removed once the token is sealed.
+	legacy *fileStore
+}
+
+func newEncryptedStore(opts StoreOptions) (*encryptedStore, error) {
+	legacy, err := newFileStore(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedStore{
+		profile:    opts.Profile,
+		path:       legacy.path + encryptedSuffix,
+		keyFile:    opts.KeyFile,
+		passphrase: opts.Passphrase,
+		legacy:     legacy,
+	}, nil
+}
+
+func (e *encryptedStore) Set(_ context.Context, token string) error {
+	sealed := sealedToken{Version: sealedVersion, Salt: make([]byte, 16), Nonce: make([]byte, 12)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return err
+	}
+	key, err := e.deriveKey(&sealed, true)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, []byte(token), []byte(e.profile))
+	b, err := json.Marshal(sealed)
+	if err != nil {
+		return err
+	}
+	if err := util.AtomicWriteFile(e.path, b, 0o600); err != nil {
+		return err
+	}
+	return e.legacy.Delete(context.Background())
+}
+
+func (e *encryptedStore) Get(ctx context.Context) (string, error) {
+	b, err := os.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return e.legacy.Get(ctx)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read encrypted token: %w", err)
+	}
+	var sealed sealedToken
+	if err := json.Unmarshal(b, &sealed); err != nil || sealed.Version != sealedVersion {
+		return "", fmt.Errorf("encrypted token file %s is corrupt; run `codemint auth login`", e.path)
+	}
+	key, err := e.deriveKey(&sealed, false)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(e.profile))
+	if err != nil {
+		return "", errors.New("cannot decrypt stored token: wrong passphrase or key file")
+	}
+	return string(plain), nil
+}
+
+func (e *encryptedStore) Delete(ctx context.Context) error {
+	if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return e.legacy.Delete(ctx)
+}
+
+// deriveKey picks the key source: a key file when configured, otherwise a
+// passphrase. When sealing it records the choice in s; when opening it
+// follows the choice already recorded.
+func (e *encryptedStore) deriveKey(s *sealedToken, sealing bool) ([]byte, error) {
+	if sealing {
+		s.KDF = kdfPassphrase
+		s.Iterations = pbkdf2Iter
+		if e.keyFile != "" {
+			s.KDF, s.Iterations = kdfKeyFile, 0
+		}
+	}
+	switch s.KDF {
+	case kdfKeyFile:
+		if e.keyFile == "" {
+			return nil, errors.New("stored token was sealed with a key file; set token_store.key_file")
+		}
+		material, err := os.ReadFile(e.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read token key file: %w", err)
+		}
+		material = []byte(strings.TrimSpace(string(material)))
+		if len(material) < 32 {
+			return nil, fmt.Errorf("token key file %s must hold at least 32 bytes", e.keyFile)
+		}
+		mac := hmac.New(sha256.New, material)
+		mac.Write(s.Salt)
+		return mac.Sum(nil), nil
+	case kdfPassphrase:
+		pass, err := e.readPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if s.Iterations <= 0 {
+			return nil, errors.New("encrypted token file has no iteration count")
+		}
+		return pbkdf2SHA256([]byte(pass), s.Salt, s.Iterations, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported token key derivation %q", s.KDF)
+	}
+}
+
+func (e *encryptedStore) readPassphrase() (string, error) {
+	if v := os.Getenv(EnvTokenPassphrase); v != "" {
+		return v, nil
+	}
+	if e.passphrase == nil {
+		return "", ErrNoTokenKey
+	}
+	pass, err := e.passphrase()
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", ErrNoTokenKey
+	}
+	return pass, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
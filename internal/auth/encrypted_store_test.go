@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codemint/codemint-cli/internal/config"
+)
+
+func TestPBKDF2SHA256Vectors(t *testing.T) {
+	cases := []struct {
+		iter int
+		want string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	}
+	for _, tc := range cases {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte("password"), []byte("salt"), tc.iter, 32))
+		if got != tc.want {
+			t.Fatalf("iter %d: got %s want %s", tc.iter, got, tc.want)
+		}
+	}
+}
+
+func TestEncryptedStoreSealsAndMigrates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.EnvConfigDir, t.TempDir())
+	t.Setenv(EnvTokenPassphrase, "correct horse")
+	reqCtx := context.Background()
+
+	legacy, err := newFileStore("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Set(reqCtx, "plain-token"); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := MigrateToEncrypted(reqCtx, StoreOptions{Profile: "work"})
+	if err != nil || !ok {
+		t.Fatalf("MigrateToEncrypted = %v, %v", ok, err)
+	}
+	if _, err := os.Stat(legacy.path); !os.IsNotExist(err) {
+		t.Fatalf("plaintext file still present: %v", err)
+	}
+	sealed, err := os.ReadFile(legacy.path + encryptedSuffix)
+	if err != nil || strings.Contains(string(sealed), "plain-token") {
+		t.Fatalf("sealed file missing or readable: %v", err)
+	}
+
+	store, err := NewStore(StoreOptions{Profile: "work", Backend: BackendEncrypted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := store.Get(reqCtx); err != nil || tok != "plain-token" {
+		t.Fatalf("Get = %q, %v", tok, err)
+	}
+
+	t.Setenv(EnvTokenPassphrase, "wrong")
+	if _, err := store.Get(reqCtx); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+	t.Setenv(EnvTokenPassphrase, "")
+	if _, err := store.Get(reqCtx); err != ErrNoTokenKey {
+		t.Fatalf("expected locked store, got %v", err)
+	}
+}
+
+func TestPlaintextProfilesListsOnlyProfileNames(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.EnvConfigDir, t.TempDir())
+	reqCtx := context.Background()
+	for _, profile := range []string{"default", "team_2", "staging-eu"} {
+		fs, err := newFileStore(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.Set(reqCtx, "t"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fs.path+encryptedSuffix, []byte("sealed"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profiles, err := PlaintextProfiles()
+	if err != nil || strings.Join(profiles, ",") != "default,staging-eu,team_2" {
+		t.Fatalf("PlaintextProfiles = %v, %v", profiles, err)
+	}
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/codemint/codemint-cli/internal/api"
@@ -13,8 +15,20 @@ type LoginOptions struct {
 	BaseURL string
 	Client  *api.Client
 	Store   TokenStore
+
+	// NoBrowser prints the login URL instead of opening it.
+	NoBrowser bool
+	// CallbackHost and CallbackPort set where the loopback callback listens;
+	// a fixed port can be forwarded over SSH or from a devcontainer.
+	CallbackHost string
+	CallbackPort int
+	// Timeout bounds the wait for the browser; zero means DefaultLoginTimeout.
+	Timeout time.Duration
 }
 
+// DefaultLoginTimeout is how long Login waits for the browser callback.
+const DefaultLoginTimeout = 2 * time.Minute
+
 type LoginResult struct {
 	Email string
 }
@@ -22,7 +36,15 @@ type LoginResult struct {
 var openBrowser = OpenBrowser
 
 func Login(ctx context.Context, opts LoginOptions) (*LoginResult, error) {
-	srv, err := newCallbackServer()
+	state, err := randomURLString(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomURLString(32)
+	if err != nil {
+		return nil, err
+	}
+	srv, err := newCallbackServer(state, opts.CallbackHost, opts.CallbackPort)
 	if err != nil {
 		return nil, fmt.Errorf("start callback server: %w", err)
 	}
@@ -30,31 +52,73 @@ func Login(ctx context.Context, opts LoginOptions) (*LoginResult, error) {
 		_ = srv.Close(context.Background())
 	}()
 
-	loginURL := fmt.Sprintf("%s/cli-auth?port=%d", trimBaseURL(opts.BaseURL), srv.Port())
-	if err := openBrowser(loginURL); err != nil {
+	q := url.Values{}
+	q.Set("port", strconv.Itoa(srv.Port()))
+	q.Set("redirect_uri", srv.RedirectURI())
+	q.Set("state", state)
+	q.Set("code_challenge", pkceChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	loginURL := fmt.Sprintf("%s/cli-auth?%s", trimBaseURL(opts.BaseURL), q.Encode())
+	switch {
+	case opts.NoBrowser:
+		fmt.Printf("Open this URL in a browser to sign in:\n%s\n", loginURL)
+	case openBrowser(loginURL) != nil:
 		fmt.Printf("Could not open browser automatically. Open this URL manually:\n%s\n", loginURL)
 	}
 
-	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLoginTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	payload, err := srv.WaitForToken(waitCtx)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("no response from the browser after %s; rerun with a longer --timeout, or use `codemint auth login --device`", timeout)
+	}
+	var cerr *CallbackError
+	if errors.As(err, &cerr) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("waiting for auth callback: %w", err)
 	}
-	if err := opts.Store.Set(ctx, payload.Token); err != nil {
-		return nil, fmt.Errorf("persist token: %w", err)
+	if payload.Code != "" {
+		exchanged, err := opts.Client.ExchangeAuthCode(ctx, api.AuthCodeExchangeRequest{Code: payload.Code, CodeVerifier: verifier, RedirectURI: srv.RedirectURI()})
+		if err != nil {
+			return nil, fmt.Errorf("exchange authorization code: %w", err)
+		}
+		payload = *exchanged
 	}
+	return finishLogin(ctx, opts, payload)
+}
 
-	me, err := opts.Client.AuthMe(ctx, payload.Token)
+// finishLogin checks the token against /api/auth/me and stores it with its
+// metadata.
+func finishLogin(ctx context.Context, opts LoginOptions, payload api.CLIAuthCallbackPayload) (*LoginResult, error) {
+	me, err := opts.Client.VerifyToken(ctx, payload.Token)
 	if err != nil {
-		_ = opts.Store.Delete(ctx)
 		return nil, fmt.Errorf("token verification failed. run `codemint auth login` again: %w", err)
 	}
-	if me != nil && me.ID == "" {
-		_ = opts.Store.Delete(ctx)
+	if me == nil || me.ID == "" {
 		return nil, fmt.Errorf("token verification failed: empty user. run `codemint auth login` again")
 	}
-
+	cred := Credential{
+		Token:        payload.Token,
+		RefreshToken: payload.RefreshToken,
+		IssuedAt:     timeAt(time.Now().UTC()),
+		BaseURL:      opts.BaseURL,
+		Email:        me.Email,
+		Scopes:       payload.Scopes,
+	}
+	if payload.ExpiresAt != "" {
+		if t, err := time.Parse(time.RFC3339, payload.ExpiresAt); err == nil {
+			cred.ExpiresAt = timeAt(t.UTC())
+		}
+	}
+	if err := SaveCredential(ctx, opts.Store, cred); err != nil {
+		return nil, fmt.Errorf("persist token: %w", err)
+	}
 	return &LoginResult{Email: me.Email}, nil
 }
 
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// helperRequest is written to the helper's stdin. Token is only set for
+// store; it is opaque to the helper and must be returned unchanged by get.
+type helperRequest struct {
+	Profile string `json:"profile"`
+	BaseURL string `json:"base_url,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+// helperResponse is what get prints on stdout. An empty token or no output
+// at all means nothing is stored.
+type helperResponse struct {
+	Token string `json:"token"`
+}
+
+// helperStore delegates storage to an external executable, in the manner of
+// git credential helpers: it runs `<helper> get|store|erase` and exchanges
+// JSON over stdin and stdout. The helper's stderr is passed through so it
+// can prompt or explain failures.
+type helperStore struct {
+	argv    []string
+	profile string
+	baseURL string
+}
+
+func newHelperStore(opts StoreOptions) (*helperStore, error) {
+	argv := strings.Fields(opts.Helper)
+	if len(argv) == 0 {
+		return nil, errors.New("credential_helper is empty")
+	}
+	return &helperStore{argv: argv, profile: opts.Profile, baseURL: opts.BaseURL}, nil
+}
+
+func (h *helperStore) Set(ctx context.Context, token string) error {
+	_, err := h.run(ctx, "store", token)
+	return err
+}
+
+func (h *helperStore) Get(ctx context.Context) (string, error) {
+	out, err := h.run(ctx, "get", "")
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return "", ErrNotLoggedIn
+	}
+	var resp helperResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("credential helper %s get: invalid JSON on stdout: %w", h.argv[0], err)
+	}
+	if resp.Token == "" {
+		return "", ErrNotLoggedIn
+	}
+	return resp.Token, nil
+}
+
+func (h *helperStore) Delete(ctx context.Context) error {
+	_, err := h.run(ctx, "erase", "")
+	return err
+}
+
+func (h *helperStore) run(ctx context.Context, op, token string) ([]byte, error) {
+	in, err := json.Marshal(helperRequest{Profile: h.profile, BaseURL: h.baseURL, Token: token})
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, h.argv[0], append(h.argv[1:], op)...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s %s: %w", h.argv[0], op, err)
+	}
+	return out, nil
+}
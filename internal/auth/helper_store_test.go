@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codemint/codemint-cli/internal/config"
+)
+
+// fakeHelper writes a shell credential helper that keeps the request for
+// store in a file and replays it on get; the extra request fields are
+// ignored when the response is decoded.
+func fakeHelper(t *testing.T) (helper, vault string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	vault = filepath.Join(dir, "vault.json")
+	script := `#!/bin/sh
+case "$2" in
+get)   if [ -f "$1" ]; then cat "$1"; fi ;;
+store) cat > "$1" ;;
+erase) rm -f "$1" ;;
+*)     echo "unknown op $2" >&2; exit 2 ;;
+esac
+`
+	helper = filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(helper, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return helper + " " + vault, vault
+}
+
+func TestHelperStoreRoundTrip(t *testing.T) {
+	helper, vault := fakeHelper(t)
+	t.Setenv(config.EnvConfigDir, t.TempDir())
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, "")
+	store, err := NewStore(StoreOptions{Profile: "work", Helper: helper, BaseURL: "https://codemint.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqCtx := context.Background()
+
+	if _, err := store.Get(reqCtx); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("empty helper: %v", err)
+	}
+	if err := SaveCredential(reqCtx, store, Credential{Token: "secret", Email: "dev@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(vault)
+	if !strings.Contains(string(raw), `"profile":"work"`) || !strings.Contains(string(raw), `"base_url":"https://codemint.example"`) {
+		t.Fatalf("store request = %s", raw)
+	}
+	cred, source, err := ResolveCredential(reqCtx, store, nil)
+	if err != nil || source != SourceHelper || cred.Token != "secret" || cred.Email != "dev@example.com" {
+		t.Fatalf("ResolveCredential = %+v, %q, %v", cred, source, err)
+	}
+	if err := store.Delete(reqCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(vault); !os.IsNotExist(err) {
+		t.Fatalf("erase left %s behind", vault)
+	}
+}
+
+func TestHelperStoreReportsFailure(t *testing.T) {
+	helper, _ := fakeHelper(t)
+	t.Setenv(config.EnvConfigDir, t.TempDir())
+	store, err := NewStore(StoreOptions{Profile: "work", Helper: helper + " extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background()); err == nil || !strings.Contains(err.Error(), "credential helper") {
+		t.Fatalf("expected helper failure, got %v", err)
+	}
+}
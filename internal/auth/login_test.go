@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+)
+
+func TestCallbackRejectsBadState(t *testing.T) {
+	srv, err := newCallbackServer("expected-state", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = srv.Close(context.Background()) }()
+
+	for _, state := range []string{"", "other-state"} {
+		q := url.Values{"token": {"injected"}, "state": {state}}
+		resp, err := http.Get(srv.RedirectURI() + "?" + q.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("state %q: status = %d, want 400", state, resp.StatusCode)
+		}
+	}
+	waitCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := srv.WaitForToken(waitCtx); err == nil {
+		t.Fatalf("callback with bad state must not deliver a token")
+	}
+}
+
+func TestLoginExchangesCodeWithPKCE(t *testing.T) {
+	ts := httptest.NewServer(devserver.New(devserver.Fixtures{}))
+	defer ts.Close()
+
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		u, err := url.Parse(loginURL)
+		if err != nil {
+			return err
+		}
+		if u.Query().Get("state") == "" || u.Query().Get("code_challenge_method") != "S256" {
+			t.Errorf("login URL lacks state or PKCE: %s", loginURL)
+		}
+		resp, err := http.Get(loginURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	store := &memStore{}
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second})
+	res, err := Login(context.Background(), LoginOptions{BaseURL: ts.URL, Client: client, Store: store})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if res.Email == "" || store.token == "" {
+		t.Fatalf("unexpected result %+v, stored %q", res, store.token)
+	}
+}
+
+func TestLoginFailsFastOnCallbackError(t *testing.T) {
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	pageChecked := make(chan struct{})
+	openBrowser = func(loginURL string) error {
+		u, err := url.Parse(loginURL)
+		if err != nil {
+			return err
+		}
+		q := url.Values{"state": {u.Query().Get("state")}, "error": {"access_denied"}, "error_description": {"User <b>cancelled</b>"}}
+		go func() {
+			defer close(pageChecked)
+			resp, err := http.Get(u.Query().Get("redirect_uri") + "?" + q.Encode())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || !strings.Contains(string(body), "User &lt;b&gt;cancelled&lt;/b&gt;") {
+				t.Errorf("unexpected failure page %q: %s", resp.Header.Get("Content-Type"), body)
+			}
+		}()
+		return nil
+	}
+
+	started := time.Now()
+	_, err := Login(context.Background(), LoginOptions{BaseURL: "http://example.invalid", Store: &memStore{}, Timeout: time.Minute})
+	var cerr *CallbackError
+	if !errors.As(err, &cerr) || cerr.Code != "access_denied" {
+		t.Fatalf("Login error = %v, want access_denied", err)
+	}
+	if time.Since(started) > 10*time.Second {
+		t.Fatalf("Login waited %s after the browser reported an error", time.Since(started))
+	}
+	<-pageChecked
+}
+
+func TestLoginTimeoutAndFixedPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		t.Errorf("browser opened despite NoBrowser: %s", loginURL)
+		return nil
+	}
+	_, err = Login(context.Background(), LoginOptions{BaseURL: "http://example.invalid", Store: &memStore{}, NoBrowser: true, CallbackPort: port, Timeout: 50 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "no response from the browser") {
+		t.Fatalf("Login error = %v, want timeout", err)
+	}
+
+	busy, err := newCallbackServer("s", "", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = busy.Close(context.Background()) }()
+	if busy.RedirectURI() != fmt.Sprintf("http://127.0.0.1:%d/callback", port) {
+		t.Fatalf("RedirectURI = %s", busy.RedirectURI())
+	}
+	if _, err := newCallbackServer("s", "", port); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("second listener on port %d: %v", port, err)
+	}
+}
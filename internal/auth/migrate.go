@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/config"
+)
+
+// PlaintextProfiles lists profiles that still have a plaintext token file.
+func PlaintextProfiles() ([]string, error) {
+	fs, err := newFileStore("default")
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(fs.path), "token-*"))
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]string, 0, len(matches))
+	for _, m := range matches {
+		name := strings.TrimPrefix(filepath.Base(m), "token-")
+		if !config.ValidProfileName(name) {
+			continue
+		}
+		profiles = append(profiles, name)
+	}
+	return profiles, nil
+}
+
+// MigrateToEncrypted seals a profile's plaintext token into the encrypted
+// store and removes the plaintext file. It reports false when the profile
+// has no plaintext token.
+func MigrateToEncrypted(ctx context.Context, opts StoreOptions) (bool, error) {
+	legacy, err := newFileStore(opts.Profile)
+	if err != nil {
+		return false, err
+	}
+	token, err := legacy.Get(ctx)
+	if errors.Is(err, ErrNotLoggedIn) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	enc, err := newEncryptedStore(opts)
+	if err != nil {
+		return false, err
+	}
+	if err := enc.Set(ctx, token); err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(legacy.path); !errors.Is(err, os.ErrNotExist) {
+		return true, errors.New("plaintext token file could not be removed: " + legacy.path)
+	}
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const (
+	// EnvAuthMode set to "oidc" makes every command exchange the CI
+	// provider's ID token instead of using a stored login.
+	EnvAuthMode = "CODEMINT_AUTH"
+	// EnvOIDCToken and EnvOIDCTokenFile supply the ID token directly, e.g.
+	// from a GitLab `id_tokens` entry.
+	EnvOIDCToken     = "CODEMINT_OIDC_TOKEN"
+	EnvOIDCTokenFile = "CODEMINT_OIDC_TOKEN_FILE"
+	// EnvOIDCAudience overrides the audience requested from GitHub Actions.
+	EnvOIDCAudience = "CODEMINT_OIDC_AUDIENCE"
+
+	// DefaultOIDCAudience is the audience CodeMint expects in ID tokens.
+	DefaultOIDCAudience = "codemint"
+
+	envGitHubRequestURL   = "ACTIONS_ID_TOKEN_REQUEST_URL"
+	envGitHubRequestToken = "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
+)
+
+var errNoOIDCToken = errors.New("no CI OIDC token found: in GitHub Actions grant `permissions: id-token: write`; elsewhere set " + EnvOIDCToken + " or " + EnvOIDCTokenFile)
+
+// oidcHTTP fetches ID tokens from the CI provider.
+var oidcHTTP = &http.Client{Timeout: 15 * time.Second}
+
+// oidcCache holds the exchanged token for the rest of the process; it is
+// never written to a TokenStore.
+var oidcCache struct {
+	mu   sync.Mutex
+	cred *Credential
+}
+
+// OIDCIDToken returns the CI provider's ID token and the provider name.
+// An explicit CODEMINT_OIDC_TOKEN[_FILE] wins over GitHub's request URL.
+func OIDCIDToken(ctx context.Context) (string, string, error) {
+	provider := ciProvider()
+	if v := strings.TrimSpace(os.Getenv(EnvOIDCToken)); v != "" {
+		return v, provider, nil
+	}
+	if path := strings.TrimSpace(os.Getenv(EnvOIDCTokenFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", provider, fmt.Errorf("read %s: %w", EnvOIDCTokenFile, err)
+		}
+		if v := strings.TrimSpace(string(b)); v != "" {
+			return v, provider, nil
+		}
+		return "", provider, fmt.Errorf("OIDC token file %s is empty", path)
+	}
+	reqURL, reqToken := os.Getenv(envGitHubRequestURL), os.Getenv(envGitHubRequestToken)
+	if reqURL == "" || reqToken == "" {
+		return "", provider, errNoOIDCToken
+	}
+	tok, err := githubIDToken(ctx, reqURL, reqToken)
+	return tok, "github", err
+}
+
+func ciProvider() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return "github"
+	case os.Getenv("GITLAB_CI") == "true":
+		return "gitlab"
+	}
+	return "generic"
+}
+
+// githubIDToken asks the Actions runtime for an ID token with CodeMint's
+// audience.
+func githubIDToken(ctx context.Context, reqURL, reqToken string) (string, error) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", envGitHubRequestURL, err)
+	}
+	audience := os.Getenv(EnvOIDCAudience)
+	if audience == "" {
+		audience = DefaultOIDCAudience
+	}
+	q := u.Query()
+	q.Set("audience", audience)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+reqToken)
+	req.Header.Set("Accept", "application/json")
+	resp, err := oidcHTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request GitHub OIDC token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("request GitHub OIDC token: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	var out struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.Value == "" {
+		return "", errors.New("request GitHub OIDC token: response has no value")
+	}
+	return out.Value, nil
+}
+
+// OIDCCredential exchanges the CI provider's ID token for a short-lived CLI
+// token. The result is cached for the process until it expires.
+func OIDCCredential(ctx context.Context, client *api.Client) (Credential, error) {
+	oidcCache.mu.Lock()
+	defer oidcCache.mu.Unlock()
+	if c := oidcCache.cred; c != nil && !c.ExpiresWithin(30*time.Second) {
+		return *c, nil
+	}
+	idToken, provider, err := OIDCIDToken(ctx)
+	if err != nil {
+		return Credential{}, err
+	}
+	out, err := client.ExchangeOIDCToken(ctx, api.OIDCExchangeRequest{IDToken: idToken, Provider: provider})
+	if err != nil {
+		return Credential{}, fmt.Errorf("exchange %s OIDC token: %w", provider, err)
+	}
+	cred := Credential{Token: out.Token, IssuedAt: timeAt(time.Now().UTC()), Scopes: out.Scopes}
+	if t, err := time.Parse(time.RFC3339, out.ExpiresAt); err == nil {
+		cred.ExpiresAt = timeAt(t.UTC())
+	}
+	oidcCache.cred = &cred
+	return cred, nil
+}
+
+// OIDCLogin exchanges the CI ID token and verifies the result. Nothing is
+// stored: later commands repeat the exchange when CODEMINT_AUTH=oidc.
+func OIDCLogin(ctx context.Context, opts LoginOptions) (*LoginResult, error) {
+	cred, err := OIDCCredential(ctx, opts.Client)
+	if err != nil {
+		return nil, err
+	}
+	me, err := opts.Client.VerifyToken(ctx, cred.Token)
+	if err != nil {
+		return nil, fmt.Errorf("verify OIDC-issued token: %w", err)
+	}
+	return &LoginResult{Email: me.Email}, nil
+}
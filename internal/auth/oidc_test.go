@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+)
+
+func oidcEnv(t *testing.T, requestURL string) {
+	t.Helper()
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, "")
+	t.Setenv(EnvOIDCToken, "")
+	t.Setenv(EnvOIDCTokenFile, "")
+	t.Setenv(EnvOIDCAudience, "")
+	t.Setenv(EnvAuthMode, "oidc")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv(envGitHubRequestURL, requestURL)
+	t.Setenv(envGitHubRequestToken, devserver.DefaultOIDCRequestToken)
+	oidcCache.cred = nil
+	t.Cleanup(func() { oidcCache.cred = nil })
+}
+
+func TestOIDCExchangeKeepsTokenInMemory(t *testing.T) {
+	ts := httptest.NewServer(devserver.New(devserver.Fixtures{}))
+	defer ts.Close()
+	oidcEnv(t, ts.URL+"/oidc/token?api-version=2.0")
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second})
+	store := &memStore{}
+	reqCtx := context.Background()
+
+	cred, source, err := ResolveCredential(reqCtx, store, client)
+	if err != nil || source != SourceOIDC || cred.Token == "" || cred.ExpiresAt == nil {
+		t.Fatalf("ResolveCredential = %+v, %q, %v", cred, source, err)
+	}
+	if _, err := client.AuthMe(reqCtx, cred.Token); err != nil {
+		t.Fatalf("exchanged token rejected: %v", err)
+	}
+	again, _, _ := ResolveCredential(reqCtx, store, client)
+	if again.Token != cred.Token {
+		t.Fatalf("second resolve exchanged again: %q != %q", again.Token, cred.Token)
+	}
+	if store.token != "" {
+		t.Fatalf("OIDC token was persisted: %q", store.token)
+	}
+	if res, err := OIDCLogin(reqCtx, LoginOptions{Client: client, Store: store}); err != nil || res.Email == "" || store.token != "" {
+		t.Fatalf("OIDCLogin = %+v, %v (stored %q)", res, err, store.token)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongAudience(t *testing.T) {
+	ts := httptest.NewServer(devserver.New(devserver.Fixtures{}))
+	defer ts.Close()
+	oidcEnv(t, ts.URL+"/oidc/token")
+	t.Setenv(EnvOIDCAudience, "someone-else")
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second})
+
+	_, err := OIDCCredential(context.Background(), client)
+	if !errors.Is(err, api.ErrUnauthorized) || !strings.Contains(err.Error(), "github") {
+		t.Fatalf("expected unauthorized exchange, got %v", err)
+	}
+}
+
+func TestOIDCIDTokenFromEnv(t *testing.T) {
+	oidcEnv(t, "")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv(EnvOIDCToken, "header.claims.sig")
+	tok, provider, err := OIDCIDToken(context.Background())
+	if err != nil || tok != "header.claims.sig" || provider != "gitlab" {
+		t.Fatalf("OIDCIDToken = %q, %q, %v", tok, provider, err)
+	}
+	t.Setenv(EnvOIDCToken, "")
+	t.Setenv(envGitHubRequestToken, "")
+	if _, _, err := OIDCIDToken(context.Background()); !errors.Is(err, errNoOIDCToken) {
+		t.Fatalf("expected errNoOIDCToken, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// pbkdf2SHA256 derives keyLen bytes from password and salt as specified in
+// RFC 8018 section 5.2, using HMAC-SHA256 as the PRF.
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	out := make([]byte, 0, blocks*hashLen)
+	var counter [4]byte
+	u := make([]byte, hashLen)
+	t := make([]byte, hashLen)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
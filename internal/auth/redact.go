@@ -2,8 +2,18 @@ package auth
 
 import "regexp"
 
-var bearerRe = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`)
+// secretField matches the names of values that can be traded for a token:
+// tokens themselves, authorization codes, PKCE verifiers and device codes.
+const secretField = `(?:\w*token|code|code_?verifier|device_?code)`
+
+var (
+	bearerRe    = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`)
+	tokenParam  = regexp.MustCompile(`(?i)\b(` + secretField + `=)[^&\s"']+`)
+	tokenJSONRe = regexp.MustCompile(`(?i)("` + secretField + `"\s*:\s*")[^"]*"`)
+)
 
 func RedactToken(input string) string {
-	return bearerRe.ReplaceAllString(input, "Bearer [REDACTED]")
+	out := bearerRe.ReplaceAllString(input, "Bearer [REDACTED]")
+	out = tokenParam.ReplaceAllString(out, "${1}[REDACTED]")
+	return tokenJSONRe.ReplaceAllString(out, `${1}[REDACTED]"`)
 }
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/config"
+)
+
+const (
+	lockPollInterval = 50 * time.Millisecond
+	// staleLockAge is how old a lock file must be before it is assumed to
+	// belong to a crashed process.
+	staleLockAge = 30 * time.Second
+)
+
+var (
+	errNoRefreshToken = errors.New("no refresh token stored")
+	// errNotStoredToken means a 401 came back for a token this store never
+	// held, so the stored session must not be used in its place.
+	errNotStoredToken = errors.New("the rejected token is not the stored one; not refreshing")
+)
+
+// Refresher renews the stored token with its refresh token. It serializes
+// refreshes within the process with a mutex and across processes with a lock
+// file, and re-reads the store first so only one caller hits the endpoint.
+type Refresher struct {
+	Store    TokenStore
+	Client   *api.Client
+	LockPath string
+
+	mu sync.Mutex
+}
+
+// NewRefresher returns a Refresher that locks a file next to the profile's
+// token store.
+func NewRefresher(profile string, store TokenStore, client *api.Client) (*Refresher, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Refresher{
+		Store:    store,
+		Client:   client,
+		LockPath: filepath.Join(dir, "refresh-"+profile+".lock"),
+	}, nil
+}
+
+// Refresh returns a token to use instead of stale, refreshing it if no other
+// caller already has.
+func (r *Refresher) Refresh(ctx context.Context, stale string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unlock, err := lockFile(ctx, r.LockPath)
+	if err != nil {
+		return "", fmt.Errorf("lock token refresh: %w", err)
+	}
+	defer unlock()
+
+	cred, err := LoadCredential(ctx, r.Store)
+	if err != nil {
+		return "", err
+	}
+	if cred.Token != stale {
+		// Another caller may have rotated stale out already; anything
+		// else was never stored and must not be replaced by the session.
+		if cred.Replaced == "" || cred.Replaced != tokenHash(stale) {
+			return "", errNotStoredToken
+		}
+		if !cred.Expired() {
+			return cred.Token, nil
+		}
+	}
+	if cred.RefreshToken == "" {
+		return "", errNoRefreshToken
+	}
+	out, err := r.Client.RefreshToken(ctx, cred.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	cred.Replaced = tokenHash(cred.Token)
+	cred.Token = out.Token
+	if out.RefreshToken != "" {
+		cred.RefreshToken = out.RefreshToken
+	}
+	cred.IssuedAt = timeAt(time.Now().UTC())
+	cred.ExpiresAt = nil
+	if t, err := time.Parse(time.RFC3339, out.ExpiresAt); err == nil {
+		cred.ExpiresAt = timeAt(t.UTC())
+	}
+	if len(out.Scopes) > 0 {
+		cred.Scopes = out.Scopes
+	}
+	if err := SaveCredential(ctx, r.Store, cred); err != nil {
+		return "", fmt.Errorf("persist refreshed token: %w", err)
+	}
+	return cred.Token, nil
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// lockFile takes an exclusive lock by creating path, waiting while another
+// process holds it.
+func lockFile(ctx context.Context, path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if st, err := os.Stat(path); err == nil && time.Since(st.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
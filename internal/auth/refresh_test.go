@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+)
+
+func TestConcurrentRefreshHitsEndpointOnce(t *testing.T) {
+	srv := devserver.New(devserver.Fixtures{})
+	refreshCalls := 0
+	var callsMu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/refresh" {
+			callsMu.Lock()
+			refreshCalls++
+			callsMu.Unlock()
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		resp, err := http.Get(loginURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	store := &memStore{}
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second})
+	if _, err := Login(context.Background(), LoginOptions{BaseURL: ts.URL, Client: client, Store: store}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.RefreshToken == "" {
+		t.Fatalf("expected a stored refresh token, got %+v, %v", cred, err)
+	}
+	srv.RevokeToken(cred.Token)
+
+	r := &Refresher{Store: store, Client: client, LockPath: filepath.Join(t.TempDir(), "refresh.lock")}
+	var wg sync.WaitGroup
+	tokens := make([]string, 8)
+	errs := make([]error, 8)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = r.Refresh(context.Background(), cred.Token)
+		}(i)
+	}
+	wg.Wait()
+	for i := range tokens {
+		if errs[i] != nil || tokens[i] == "" || tokens[i] != tokens[0] {
+			t.Fatalf("refresh %d = %q, %v (first %q)", i, tokens[i], errs[i], tokens[0])
+		}
+	}
+	if refreshCalls != 1 {
+		t.Fatalf("refresh endpoint called %d times, want 1", refreshCalls)
+	}
+	if _, err := client.AuthMe(context.Background(), tokens[0]); err != nil {
+		t.Fatalf("refreshed token rejected: %v", err)
+	}
+}
+
+func TestLoginWithInvalidTokenIsNotRescuedByStoredSession(t *testing.T) {
+	ts := httptest.NewServer(devserver.New(devserver.Fixtures{}))
+	defer ts.Close()
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		resp, err := http.Get(loginURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	store := &memStore{}
+	var r *Refresher
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, Refresh: func(ctx context.Context, stale string) (string, error) {
+		return r.Refresh(ctx, stale)
+	}})
+	r = &Refresher{Store: store, Client: client, LockPath: filepath.Join(t.TempDir(), "refresh.lock")}
+	if _, err := Login(context.Background(), LoginOptions{BaseURL: ts.URL, Client: client, Store: store}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	before := store.token
+
+	opts := LoginOptions{BaseURL: ts.URL, Client: client, Store: store}
+	if res, err := LoginWithToken(context.Background(), opts, strings.NewReader("garbage-token\n")); err == nil {
+		t.Fatalf("garbage token accepted as %+v", res)
+	}
+	if store.token != before {
+		t.Fatalf("stored credential changed to %s", store.token)
+	}
+	if _, err := r.Refresh(context.Background(), "garbage-token"); !errors.Is(err, errNotStoredToken) {
+		t.Fatalf("Refresh of a foreign token = %v, want errNotStoredToken", err)
+	}
+}
+
+func TestRevokeCredentialRevokesRefreshToken(t *testing.T) {
+	srv := devserver.New(devserver.Fixtures{})
+	var revoked []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/refresh/revoke" || (r.URL.Path == "/api/auth/cli-token" && r.Method == http.MethodDelete) {
+			revoked = append(revoked, r.URL.Path)
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		resp, err := http.Get(loginURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	store := &memStore{}
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second})
+	if _, err := Login(context.Background(), LoginOptions{BaseURL: ts.URL, Client: client, Store: store}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.RefreshToken == "" {
+		t.Fatalf("expected a stored refresh token, got %+v, %v", cred, err)
+	}
+
+	if err := RevokeCredential(context.Background(), client, cred); err != nil {
+		t.Fatalf("RevokeCredential: %v", err)
+	}
+	if strings.Join(revoked, ",") != "/api/auth/cli-token,/api/auth/refresh/revoke" {
+		t.Fatalf("server saw revocations %v", revoked)
+	}
+	if _, err := client.AuthMe(context.Background(), cred.Token); !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("revoked token still accepted: %v", err)
+	}
+	if _, err := client.RefreshToken(context.Background(), cred.RefreshToken); !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("revoked refresh token still accepted: %v", err)
+	}
+}
+
+func TestRevokeCredentialWithExpiredTokenLeavesNoRefreshToken(t *testing.T) {
+	srv := devserver.New(devserver.Fixtures{})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	orig := openBrowser
+	defer func() { openBrowser = orig }()
+	openBrowser = func(loginURL string) error {
+		resp, err := http.Get(loginURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	store := &memStore{}
+	var r *Refresher
+	client := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, Refresh: func(ctx context.Context, stale string) (string, error) {
+		return r.Refresh(ctx, stale)
+	}})
+	r = &Refresher{Store: store, Client: client, LockPath: filepath.Join(t.TempDir(), "refresh.lock")}
+	if _, err := Login(context.Background(), LoginOptions{BaseURL: ts.URL, Client: client, Store: store}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.RefreshToken == "" {
+		t.Fatalf("expected a stored refresh token, got %+v, %v", cred, err)
+	}
+	srv.RevokeToken(cred.Token)
+	before := store.token
+
+	if err := RevokeCredential(context.Background(), client, cred); err != nil {
+		t.Fatalf("RevokeCredential: %v", err)
+	}
+	if store.token != before {
+		t.Fatal("revoking refreshed the stored credential")
+	}
+	if n := srv.RefreshTokens(); n != 0 {
+		t.Fatalf("%d refresh tokens still live after revoking", n)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const (
+	// EnvToken supplies a token directly; it takes precedence over everything.
+	EnvToken = "CODEMINT_TOKEN"
+	// EnvTokenFile names a file holding the token, read on every use so the
+	// token never has to sit in the environment.
+	EnvTokenFile = "CODEMINT_TOKEN_FILE"
+)
+
+// Credential sources, as reported by `auth whoami`.
+const (
+	SourceStore  = "store"
+	SourceEnv    = "env"
+	SourceFile   = "file"
+	SourceHelper = "helper"
+	SourceOIDC   = "oidc"
+)
+
+// ExternalSource reports whether the token comes from outside the store, and
+// if so which source. Tokens from outside the store are never refreshed.
+func ExternalSource() string {
+	switch {
+	case strings.TrimSpace(os.Getenv(EnvToken)) != "":
+		return SourceEnv
+	case strings.TrimSpace(os.Getenv(EnvTokenFile)) != "":
+		return SourceFile
+	case strings.EqualFold(os.Getenv(EnvAuthMode), "oidc"):
+		return SourceOIDC
+	}
+	return ""
+}
+
+// ResolveCredential returns the active credential and its source:
+// CODEMINT_TOKEN, then CODEMINT_TOKEN_FILE, then a CI OIDC exchange when
+// CODEMINT_AUTH=oidc, then the store.
+func ResolveCredential(ctx context.Context, store TokenStore, client *api.Client) (Credential, string, error) {
+	switch ExternalSource() {
+	case SourceEnv:
+		return Credential{Token: strings.TrimSpace(os.Getenv(EnvToken))}, SourceEnv, nil
+	case SourceFile:
+		path := strings.TrimSpace(os.Getenv(EnvTokenFile))
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Credential{}, SourceFile, fmt.Errorf("read %s: %w", EnvTokenFile, err)
+		}
+		tok := strings.TrimSpace(string(b))
+		if tok == "" {
+			return Credential{}, SourceFile, fmt.Errorf("token file %s is empty", path)
+		}
+		return Credential{Token: tok}, SourceFile, nil
+	case SourceOIDC:
+		cred, err := OIDCCredential(ctx, client)
+		return cred, SourceOIDC, err
+	}
+	source := SourceStore
+	if _, ok := store.(*helperStore); ok {
+		source = SourceHelper
+	}
+	cred, err := LoadCredential(ctx, store)
+	return cred, source, err
+}
+
+// CacheIdentity names the account whose catalog responses may be cached,
+// without calling the server: the stored login's email, otherwise a hash of
+// the token. It is empty when there is no token yet, including OIDC before
+// its exchange, so nothing is cached for or served to an unknown account.
+func CacheIdentity(ctx context.Context, store TokenStore) string {
+	if ExternalSource() == SourceOIDC {
+		return ""
+	}
+	cred, _, err := ResolveCredential(ctx, store, nil)
+	switch {
+	case err != nil || cred.Token == "":
+		return ""
+	case cred.Email != "":
+		return "email:" + cred.Email
+	}
+	return "token:" + tokenHash(cred.Token)[:16]
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+func TestResolveCredentialPrecedence(t *testing.T) {
+	reqCtx := context.Background()
+	store := &memStore{token: "stored"}
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, "")
+
+	if cred, src, err := ResolveCredential(reqCtx, store, nil); err != nil || src != SourceStore || cred.Token != "stored" {
+		t.Fatalf("store: %+v %q %v", cred, src, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvTokenFile, path)
+	if cred, src, err := ResolveCredential(reqCtx, store, nil); err != nil || src != SourceFile || cred.Token != "from-file" {
+		t.Fatalf("file: %+v %q %v", cred, src, err)
+	}
+	if err := os.WriteFile(path, []byte("rotated"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cred, _, _ := ResolveCredential(reqCtx, store, nil); cred.Token != "rotated" {
+		t.Fatalf("token file is not re-read: %q", cred.Token)
+	}
+
+	t.Setenv(EnvToken, "from-env")
+	if cred, src, err := ResolveCredential(reqCtx, store, nil); err != nil || src != SourceEnv || cred.Token != "from-env" {
+		t.Fatalf("env: %+v %q %v", cred, src, err)
+	}
+}
+
+func TestCacheIdentity(t *testing.T) {
+	reqCtx := context.Background()
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, "")
+	t.Setenv(EnvAuthMode, "")
+	store := &memStore{}
+	if id := CacheIdentity(reqCtx, store); id != "" {
+		t.Fatalf("logged out identity = %q", id)
+	}
+	store.token = "bare-token"
+	bare := CacheIdentity(reqCtx, store)
+	if !strings.HasPrefix(bare, "token:") || strings.Contains(bare, "bare-token") {
+		t.Fatalf("bare token identity = %q", bare)
+	}
+	if err := SaveCredential(reqCtx, store, Credential{Token: "t1", Email: "dev@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if id := CacheIdentity(reqCtx, store); id != "email:dev@example.com" {
+		t.Fatalf("stored login identity = %q", id)
+	}
+	t.Setenv(EnvToken, "ci-token")
+	if id := CacheIdentity(reqCtx, store); id == bare || !strings.HasPrefix(id, "token:") {
+		t.Fatalf("env token identity = %q", id)
+	}
+}
+
+func TestLoginWithToken(t *testing.T) {
+	srv := deviceServer(t, false)
+	store := &memStore{}
+	opts := LoginOptions{
+		BaseURL: srv.URL,
+		Client:  api.NewClient(api.ClientOptions{BaseURL: srv.URL, Timeout: 2 * time.Second, UserAgent: "test/1"}),
+		Store:   store,
+	}
+	res, err := LoginWithToken(context.Background(), opts, strings.NewReader("device-token\n"))
+	if err != nil {
+		t.Fatalf("LoginWithToken: %v", err)
+	}
+	if res.Email != "dev@example.com" {
+		t.Fatalf("email = %q", res.Email)
+	}
+	cred, err := LoadCredential(context.Background(), store)
+	if err != nil || cred.Token != "device-token" || cred.Email != "dev@example.com" {
+		t.Fatalf("stored credential = %+v, %v", cred, err)
+	}
+
+	store.token = ""
+	if _, err := LoginWithToken(context.Background(), opts, strings.NewReader("bogus")); err == nil {
+		t.Fatal("expected an unverified token to be rejected")
+	}
+	if store.token != "" {
+		t.Fatal("rejected token was stored")
+	}
+	if _, err := LoginWithToken(context.Background(), opts, strings.NewReader("  \n")); err == nil {
+		t.Fatal("expected empty input to be rejected")
+	}
+}
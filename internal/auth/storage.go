@@ -3,8 +3,11 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/codemint/codemint-cli/internal/config"
 )
 
 // ErrNotLoggedIn is returned when no stored token exists (e.g. keychain item not found).
@@ -16,11 +19,62 @@ type TokenStore interface {
 	Delete(ctx context.Context) error
 }
 
+// Token store backends for StoreOptions.Backend.
+const (
+	BackendAuto      = "auto"
+	BackendKeychain  = "keychain"
+	BackendEncrypted = "encrypted"
+	BackendFile      = "file"
+)
+
+type StoreOptions struct {
+	Profile string
+	// Backend is one of the Backend constants; empty means BackendAuto,
+	// which uses the OS keychain where there is one and otherwise a file
+	// that is encrypted when key material is configured.
+	Backend string
+	// KeyFile seals the encrypted store with the file's contents instead of
+	// a passphrase.
+	KeyFile string
+	// Passphrase prompts for the encrypted store's passphrase when
+	// EnvTokenPassphrase is not set.
+	Passphrase func() (string, error)
+	// Helper is a credential helper command line; when set it replaces
+	// Backend. BaseURL is passed to the helper alongside the profile.
+	Helper  string
+	BaseURL string
+}
+
 func NewTokenStore(profile string) (TokenStore, error) {
-	if profile == "" {
-		profile = "default"
+	return NewStore(StoreOptions{Profile: profile})
+}
+
+func NewStore(opts StoreOptions) (TokenStore, error) {
+	if opts.Profile == "" {
+		opts.Profile = "default"
 	}
-	return newPlatformStore(profile)
+	if opts.Helper != "" {
+		return newHelperStore(opts)
+	}
+	switch opts.Backend {
+	case "", BackendAuto, BackendKeychain:
+		return newPlatformStore(opts)
+	case BackendEncrypted:
+		return newEncryptedStore(opts)
+	case BackendFile:
+		return newFileStore(opts.Profile)
+	default:
+		return nil, fmt.Errorf("unknown token store backend %q (use auto, keychain, encrypted or file)", opts.Backend)
+	}
+}
+
+// newFallbackStore is used where there is no OS keychain: the encrypted
+// store when a key is configured, otherwise the plaintext file.
+func newFallbackStore(opts StoreOptions) (TokenStore, error) {
+	if opts.KeyFile != "" || os.Getenv(EnvTokenPassphrase) != "" {
+		return newEncryptedStore(opts)
+	}
+	return newFileStore(opts.Profile)
 }
 
 type fileStore struct {
@@ -28,11 +82,10 @@ type fileStore struct {
 }
 
 func newFileStore(profile string) (*fileStore, error) {
-	home, err := os.UserHomeDir()
+	dir, err := config.Dir()
 	if err != nil {
 		return nil, err
 	}
-	dir := filepath.Join(home, ".config", "codemint")
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return nil, err
 	}
@@ -45,8 +98,11 @@ func (f *fileStore) Set(_ context.Context, token string) error {
 
 func (f *fileStore) Get(_ context.Context) (string, error) {
 	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotLoggedIn
+	}
 	if err != nil {
-		return "", errors.New("no local token found")
+		return "", fmt.Errorf("read local token: %w", err)
 	}
 	return string(b), nil
 }
@@ -15,8 +15,8 @@ type darwinStore struct {
 	service string
 }
 
-func newPlatformStore(profile string) (TokenStore, error) {
-	return &darwinStore{account: profile, service: "codemint-cli"}, nil
+func newPlatformStore(opts StoreOptions) (TokenStore, error) {
+	return &darwinStore{account: opts.Profile, service: "codemint-cli"}, nil
 }
 
 func (d *darwinStore) Set(_ context.Context, token string) error {
@@ -2,6 +2,6 @@
 
 package auth
 
-func newPlatformStore(profile string) (TokenStore, error) {
-	return newFileStore(profile)
+func newPlatformStore(opts StoreOptions) (TokenStore, error) {
+	return newFallbackStore(opts)
 }
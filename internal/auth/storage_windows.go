@@ -11,15 +11,15 @@ import (
 
 type windowsStore struct {
 	target   string
-	fallback *fileStore
+	fallback TokenStore
 }
 
-func newPlatformStore(profile string) (TokenStore, error) {
-	fs, err := newFileStore(profile)
+func newPlatformStore(opts StoreOptions) (TokenStore, error) {
+	fs, err := newFallbackStore(opts)
 	if err != nil {
 		return nil, err
 	}
-	return &windowsStore{target: "codemint-" + profile, fallback: fs}, nil
+	return &windowsStore{target: "codemint-" + opts.Profile, fallback: fs}, nil
 }
 
 func (w *windowsStore) Set(ctx context.Context, token string) error {
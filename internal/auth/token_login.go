@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+// maxTokenInput bounds what LoginWithToken reads, so a mistaken pipe cannot
+// exhaust memory.
+const maxTokenInput = 64 << 10
+
+// LoginWithToken reads a token from r, verifies it and stores it, for CI and
+// scripts that already hold a token.
+func LoginWithToken(ctx context.Context, opts LoginOptions, r io.Reader) (*LoginResult, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxTokenInput+1))
+	if err != nil {
+		return nil, fmt.Errorf("read token: %w", err)
+	}
+	if len(b) > maxTokenInput {
+		return nil, errors.New("token input is too large")
+	}
+	tok := strings.TrimSpace(string(b))
+	if tok == "" {
+		return nil, errors.New("no token on standard input")
+	}
+	if strings.ContainsAny(tok, " \t\r\n") {
+		return nil, errors.New("standard input must contain exactly one token")
+	}
+	return finishLogin(ctx, opts, api.CLIAuthCallbackPayload{Token: tok})
+}
@@ -1,53 +1,170 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
-	"os"
+	"fmt"
 	"path/filepath"
+	"regexp"
+	"time"
 )
 
 const defaultBaseURL = "https://codemint.app"
 
 type Config struct {
-	BaseURL string `json:"base_url"`
-	Profile string `json:"profile"`
+	BaseURL string      `json:"base_url"`
+	Profile string      `json:"profile"`
+	Proxy   string      `json:"proxy,omitempty"`
+	Org     string      `json:"org,omitempty"`
+	Retry   RetryConfig `json:"retry"`
+	Cache   CacheConfig `json:"cache"`
+	Offline bool        `json:"offline,omitempty"`
+	Sync    SyncConfig  `json:"sync"`
+	TLS     TLSConfig   `json:"tls"`
+	// TokenStore selects where credentials are kept.
+	TokenStore TokenStoreConfig `json:"token_store"`
+	// CredentialHelper is an executable, with arguments, that stores
+	// tokens in place of TokenStore; it is run with get, store or erase.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+	// Profiles holds per-profile overrides keyed by profile name.
+	Profiles map[string]ProfileConfig `json:"profiles,omitempty"`
+}
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// profileNameRule is appended to errors about invalid profile names.
+const profileNameRule = "use only letters, digits, '-' and '_'"
+
+// ValidProfileName reports whether name may name a profile. Profile names
+// become part of token file names, so only letters, digits, '-' and '_'
+// are allowed.
+func ValidProfileName(name string) bool {
+	return profileNameRe.MatchString(name)
+}
+
+// ProfileConfig overrides the top-level settings while the profile is
+// active.
+type ProfileConfig struct {
+	BaseURL string    `json:"base_url,omitempty"`
+	Proxy   string    `json:"proxy,omitempty"`
+	Org     string    `json:"org,omitempty"`
+	TLS     TLSConfig `json:"tls"`
+}
+
+type TLSConfig struct {
+	CAFile     string `json:"ca_file,omitempty"`
+	CertFile   string `json:"cert_file,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	MinVersion string `json:"min_version,omitempty"`
+	// InsecureSkipVerify disables certificate verification; development only.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+	// Pins are base64 SHA-256 SPKI hashes ("sha256/...") the server must match.
+	Pins []string `json:"pins,omitempty"`
+}
+
+// Merge overlays the non-empty fields of o onto t.
+func (t TLSConfig) Merge(o TLSConfig) TLSConfig {
+	if o.CAFile != "" {
+		t.CAFile = o.CAFile
+	}
+	if o.CertFile != "" {
+		t.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		t.KeyFile = o.KeyFile
+	}
+	if o.MinVersion != "" {
+		t.MinVersion = o.MinVersion
+	}
+	if o.InsecureSkipVerify {
+		t.InsecureSkipVerify = true
+	}
+	if len(o.Pins) > 0 {
+		t.Pins = o.Pins
+	}
+	return t
+}
+
+type TokenStoreConfig struct {
+	// Backend is auto, keychain, encrypted or file.
+	Backend string `json:"backend,omitempty"`
+	// KeyFile unlocks the encrypted store instead of a passphrase.
+	KeyFile string `json:"key_file,omitempty"`
+}
+
+type SyncConfig struct {
+	// Concurrency caps parallel catalog sync batches; 0 uses the client default.
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+const defaultCacheTTL = Duration(5 * time.Minute)
+
+type CacheConfig struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// TTL is how long responses are reused without revalidation; "0s" means
+	// always revalidate.
+	TTL *Duration `json:"ttl,omitempty"`
+}
+
+func (c CacheConfig) EffectiveTTL() time.Duration {
+	if c.TTL == nil {
+		return defaultCacheTTL.Std()
+	}
+	return c.TTL.Std()
+}
+
+type RetryConfig struct {
+	MaxAttempts int      `json:"max_attempts,omitempty"`
+	BaseDelay   Duration `json:"base_delay,omitempty"`
+	MaxDelay    Duration `json:"max_delay,omitempty"`
+	Budget      Duration `json:"budget,omitempty"`
 }
 
 type LoadOptions struct {
 	ConfigPath      string
 	BaseURLOverride string
 	ProfileOverride string
+	Offline         bool
+	TLSOverride     TLSConfig
+	// RepoDir is the repository whose .codemint/config.json applies, if any.
+	RepoDir     string
+	OrgOverride string
 }
 
+// Load returns the merged configuration; see Resolve for the layers.
 func Load(opts LoadOptions) (Config, error) {
-	cfg := Config{BaseURL: defaultBaseURL, Profile: "default"}
-	path := opts.ConfigPath
-	if path == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return Config{}, err
-		}
-		path = filepath.Join(home, ".config", "codemint", "config.json")
+	r, err := Resolve(opts)
+	if err != nil {
+		return Config{}, err
 	}
+	return r.Config, nil
+}
 
-	if b, err := os.ReadFile(path); err == nil {
-		if err := json.Unmarshal(b, &cfg); err != nil {
-			return Config{}, errors.New("invalid config file")
-		}
+// Path returns path, or the user config file in Dir when it is empty.
+func Path(path string) (string, error) {
+	if path != "" {
+		return path, nil
 	}
-
-	if v := EnvBaseURL(); v != "" {
-		cfg.BaseURL = v
+	dir, err := Dir()
+	if err != nil {
+		return "", err
 	}
-	if v := EnvProfile(); v != "" {
-		cfg.Profile = v
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// LoadFile reads the config file as written, without profile, environment
+// or flag overrides. A missing file yields the defaults.
+func LoadFile(path string) (Config, error) {
+	cfg := Config{BaseURL: defaultBaseURL, Profile: "default"}
+	_, b, err := readLayer(path, false)
+	if err != nil {
+		return Config{}, err
 	}
-	if opts.BaseURLOverride != "" {
-		cfg.BaseURL = opts.BaseURLOverride
+	if len(bytes.TrimSpace(b)) == 0 {
+		return cfg, nil
 	}
-	if opts.ProfileOverride != "" {
-		cfg.Profile = opts.ProfileOverride
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
 	}
 	return cfg, nil
 }
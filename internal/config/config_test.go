@@ -0,0 +1,288 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfileSettingsOverrideTopLevel(t *testing.T) {
+	t.Setenv("CODEMINT_BASE_URL", "")
+	t.Setenv("CODEMINT_PROFILE", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "base_url": "https://codemint.app",
+  "profile": "staging",
+  "proxy": "http://proxy.corp:3128",
+  "profiles": {"staging": {"base_url": "https://staging.codemint.app", "org": "acme", "tls": {"min_version": "1.3"}}}
+}`)
+
+	cfg, err := Load(LoadOptions{ConfigPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://staging.codemint.app" || cfg.Org != "acme" || cfg.Proxy != "http://proxy.corp:3128" || cfg.TLS.MinVersion != "1.3" {
+		t.Fatalf("active profile not applied: %+v", cfg)
+	}
+
+	cfg, err = Load(LoadOptions{ConfigPath: path, ProfileOverride: "default", BaseURLOverride: "http://127.0.0.1:8787"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "http://127.0.0.1:8787" || cfg.Org != "" || cfg.TLS.MinVersion != "" {
+		t.Fatalf("overrides not applied: %+v", cfg)
+	}
+}
+
+func TestOrgPrecedence(t *testing.T) {
+	t.Setenv("CODEMINT_BASE_URL", "")
+	t.Setenv("CODEMINT_PROFILE", "")
+	t.Setenv("CODEMINT_ORG", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"profile": "work", "profiles": {"work": {"org": "acme"}}}`)
+	for _, tc := range []struct {
+		repo, env, flag, want string
+	}{
+		{want: "acme"},
+		{repo: "acme-labs", want: "acme-labs"},
+		{repo: "acme-labs", env: "initech", want: "initech"},
+		{repo: "acme-labs", env: "initech", flag: "globex", want: "globex"},
+	} {
+		t.Setenv("CODEMINT_ORG", tc.env)
+		repoDir := t.TempDir()
+		if tc.repo != "" {
+			if err := SetValue(RepoPath(repoDir), "org", tc.repo, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cfg, err := Load(LoadOptions{ConfigPath: path, RepoDir: repoDir, OrgOverride: tc.flag})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Org != tc.want {
+			t.Fatalf("repo=%q env=%q flag=%q: org = %q, want %q", tc.repo, tc.env, tc.flag, cfg.Org, tc.want)
+		}
+	}
+}
+
+func TestLayersAndOrigins(t *testing.T) {
+	t.Setenv("CODEMINT_BASE_URL", "")
+	t.Setenv("CODEMINT_PROFILE", "")
+	t.Setenv("CODEMINT_ORG", "")
+	t.Setenv("CODEMINT_RETRY_MAX_ATTEMPTS", "7")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeFile(t, path, `{
+  "profile": "work",
+  "proxy": "http://proxy.corp:3128",
+  "retry": {"max_attempts": 2, "base_delay": "100ms"},
+  "profiles": {"work": {"base_url": "https://work.codemint.app", "org": "acme"}}
+}`)
+	repo := filepath.Join(dir, "repo")
+	writeFile(t, RepoPath(repo), `{"cache": {"ttl": "30s"}, "org": "acme-labs"}`)
+
+	r, err := Resolve(LoadOptions{ConfigPath: path, RepoDir: repo, Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := r.Config
+	if cfg.BaseURL != "https://work.codemint.app" || cfg.Org != "acme-labs" || cfg.Retry.MaxAttempts != 7 ||
+		cfg.Retry.BaseDelay.Std() != 100*time.Millisecond || cfg.Cache.EffectiveTTL() != 30*time.Second || !cfg.Offline {
+		t.Fatalf("layers not merged: %+v", cfg)
+	}
+	for key, want := range map[string]string{
+		"profile":            "user (" + path + ")",
+		"proxy":              "user (" + path + ")",
+		"base_url":           "profile (work)",
+		"org":                "repo (.codemint/config.json)",
+		"cache.ttl":          "repo (.codemint/config.json)",
+		"retry.max_attempts": "env (CODEMINT_RETRY_MAX_ATTEMPTS)",
+		"offline":            "flag (--offline)",
+	} {
+		s, ok, err := r.Get(key)
+		if err != nil || !ok || s.Origin.String() != want {
+			t.Fatalf("%s: origin %q (set=%v, err=%v), want %q", key, s.Origin, ok, err, want)
+		}
+	}
+	if _, ok, _ := r.Get("tls.ca_file"); ok {
+		t.Fatal("unset key reported as set")
+	}
+
+	t.Setenv("CODEMINT_RETRY_MAX_ATTEMPTS", "many")
+	if _, err := Resolve(LoadOptions{ConfigPath: path}); err == nil || !strings.Contains(err.Error(), "CODEMINT_RETRY_MAX_ATTEMPTS") {
+		t.Fatalf("bad env value not reported: %v", err)
+	}
+}
+
+func TestFileErrorsNameTheLine(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name, body string
+		repo       bool
+		line       int
+		msg        string
+	}{
+		{"unknown key", "{\n  \"retry\": {\n    \"max_atempts\": 3\n  }\n}", false, 3, `unknown key "retry.max_atempts"`},
+		{"wrong type", "{\n  \"proxy\": \"\",\n  \"offline\": \"yes\"\n}", false, 3, "offline: want boolean"},
+		{"bad enum", "{\n  \"tls\": {\"min_version\": \"1.1\"}\n}", false, 2, "want one of 1.2, 1.3"},
+		{"profile key", "{\n  \"profiles\": {\n    \"work\": {\n      \"retry\": {}\n    }\n  }\n}", false, 4, `profiles.work: unknown key "retry"`},
+		{"syntax", "{\n  \"offline\": true,\n}", false, 2, "invalid JSON"},
+		{"profile name", "{\n  \"profiles\": {\n    \"../x\": {}\n  }\n}", false, 3, `invalid profile name "../x"`},
+		{"repo only", "{\n  \"org\": \"acme\",\n  \"base_url\": \"https://evil.example\"\n}", true, 3, "base_url cannot be set in repository config"},
+	} {
+		path := filepath.Join(dir, tc.name+".json")
+		writeFile(t, path, tc.body)
+		_, _, err := readLayer(path, tc.repo)
+		var ferr *FileError
+		if !errors.As(err, &ferr) || ferr.Line != tc.line || !strings.Contains(ferr.Msg, tc.msg) {
+			t.Fatalf("%s: got %v, want line %d with %q", tc.name, err, tc.line, tc.msg)
+		}
+		if !strings.HasPrefix(err.Error(), path+":") {
+			t.Fatalf("%s: error %q does not name the file", tc.name, err)
+		}
+	}
+}
+
+func TestSetAndUnsetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"profile": "work", "profiles": {"work": {"base_url": "https://work.codemint.app"}}}`)
+	if err := SetValue(path, "tls.pins", "sha256/a, sha256/b", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetValue(path, "sync.concurrency", "-1", false); err == nil {
+		t.Fatal("negative concurrency accepted")
+	}
+	if err := SetValue(path, "no.such.key", "1", false); err == nil {
+		t.Fatal("unknown key accepted")
+	}
+	if err := SetValue(path, "credential_helper", "pass-helper", true); err == nil {
+		t.Fatal("credential_helper accepted in repository config")
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.TLS.Pins) != 2 || cfg.TLS.Pins[1] != "sha256/b" || cfg.Profiles["work"].BaseURL != "https://work.codemint.app" {
+		t.Fatalf("set lost settings: %+v", cfg)
+	}
+	if removed, err := UnsetValue(path, "tls.pins", false); err != nil || !removed {
+		t.Fatalf("unset = %v, %v", removed, err)
+	}
+	if removed, err := UnsetValue(path, "tls.pins", false); err != nil || removed {
+		t.Fatalf("second unset = %v, %v", removed, err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), `"tls"`) {
+		t.Fatalf("empty tls object left behind:\n%s", b)
+	}
+}
+
+func TestProfileValuesEditOnlyTheirKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"proxy": "http://proxy.corp:3128"}`)
+	if err := SetProfileValues(path, "work", map[string]string{"base_url": "https://work.codemint.app", "tls.min_version": "1.3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetProfileValues(path, "work", map[string]string{"retry.max_attempts": "3"}); err == nil {
+		t.Fatal("non-profile key accepted")
+	}
+	if err := SetProfileValues(path, "work", map[string]string{"tls.min_version": "1.1"}); err == nil {
+		t.Fatal("invalid value accepted")
+	}
+	if err := SetProfileValues(path, "work", map[string]string{"org": "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, absent := range []string{`"base_url": "https://codemint.app"`, `"retry"`, `"cache"`, `"sync"`, `"token_store"`} {
+		if strings.Contains(string(b), absent) {
+			t.Fatalf("%s written to the user config:\n%s", absent, b)
+		}
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cfg.Profiles["work"]; p.BaseURL != "https://work.codemint.app" || p.Org != "acme" || p.TLS.MinVersion != "1.3" || cfg.Proxy != "http://proxy.corp:3128" {
+		t.Fatalf("profile values not saved: %+v", cfg)
+	}
+
+	if removed, err := UnsetProfileValue(path, "work", "org"); err != nil || !removed {
+		t.Fatalf("UnsetProfileValue = %v, %v", removed, err)
+	}
+	if removed, err := RemoveProfile(path, "work"); err != nil || !removed {
+		t.Fatalf("RemoveProfile = %v, %v", removed, err)
+	}
+	if removed, err := RemoveProfile(path, "work"); err != nil || removed {
+		t.Fatalf("second RemoveProfile = %v, %v", removed, err)
+	}
+	if b, _ := os.ReadFile(path); strings.Contains(string(b), "profiles") {
+		t.Fatalf("empty profiles object left behind:\n%s", b)
+	}
+}
+
+func TestProfileNameIsValidatedInEveryLayer(t *testing.T) {
+	t.Setenv("CODEMINT_BASE_URL", "")
+	t.Setenv("CODEMINT_PROFILE", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SetValue(path, "profile", "../../x", false); err == nil {
+		t.Fatal("config set accepted a path as the profile")
+	}
+
+	writeFile(t, path, `{"profile": "../../x"}`)
+	if _, err := Load(LoadOptions{ConfigPath: path}); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("profile from the user config: %v", err)
+	}
+	writeFile(t, path, `{}`)
+	t.Setenv("CODEMINT_PROFILE", "../../x")
+	if _, err := Load(LoadOptions{ConfigPath: path}); err == nil || !strings.Contains(err.Error(), "CODEMINT_PROFILE") {
+		t.Fatalf("profile from the environment: %v", err)
+	}
+	t.Setenv("CODEMINT_PROFILE", "")
+	if _, err := Load(LoadOptions{ConfigPath: path, ProfileOverride: "a/b"}); err == nil || !strings.Contains(err.Error(), "--profile") {
+		t.Fatalf("profile from the flag: %v", err)
+	}
+}
+
+func TestDirHonorsEnvironment(t *testing.T) {
+	t.Setenv(EnvConfigDir, "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if dir, err := Dir(); err != nil || dir != filepath.Join("/xdg", "codemint") {
+		t.Fatalf("Dir with XDG_CONFIG_HOME = %q, %v", dir, err)
+	}
+	t.Setenv(EnvConfigDir, "/custom")
+	if path, err := Path(""); err != nil || path != filepath.Join("/custom", "config.json") {
+		t.Fatalf("Path with %s = %q, %v", EnvConfigDir, path, err)
+	}
+}
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidProfileName(t *testing.T) {
+	for _, name := range []string{"default", "work", "staging-eu", "team_2"} {
+		if !ValidProfileName(name) {
+			t.Errorf("%q should be valid", name)
+		}
+	}
+	for _, name := range []string{"", "work.enc", "../x", "a/b", "with space"} {
+		if ValidProfileName(name) {
+			t.Errorf("%q should be invalid", name)
+		}
+	}
+}
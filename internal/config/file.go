@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FileError points at the line of a config file that could not be used.
+type FileError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+// fileLayer is a parsed config file: its settings by dotted key and, for the
+// user config, each profile's overrides.
+type fileLayer struct {
+	values   map[string]json.RawMessage
+	profiles map[string]map[string]json.RawMessage
+}
+
+type fileParser struct {
+	path string
+	src  []byte
+	dec  *json.Decoder
+	// repo rejects keys that only the user config may set.
+	repo bool
+}
+
+// parseFile validates a config file key by key. An empty file is an empty
+// config.
+func parseFile(path string, src []byte, repo bool) (fileLayer, error) {
+	layer := fileLayer{values: map[string]json.RawMessage{}, profiles: map[string]map[string]json.RawMessage{}}
+	if len(bytes.TrimSpace(src)) == 0 {
+		return layer, nil
+	}
+	p := &fileParser{path: path, src: src, dec: json.NewDecoder(bytes.NewReader(src)), repo: repo}
+	if err := p.delim('{', "the config file must hold a JSON object"); err != nil {
+		return layer, err
+	}
+	if err := p.object("", keys, layer.values, &layer); err != nil {
+		return layer, err
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return layer, p.errAt(p.dec.InputOffset(), "unexpected content after the closing brace")
+	}
+	return layer, nil
+}
+
+// object reads the members of an object whose opening brace has been
+// consumed, through its closing brace.
+func (p *fileParser) object(prefix string, schema map[string]keySpec, out map[string]json.RawMessage, root *fileLayer) error {
+	for p.dec.More() {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return p.syntax(err)
+		}
+		name, _ := tok.(string)
+		at := p.dec.InputOffset()
+		key := prefix + name
+		spec, isKey := schema[key]
+		switch {
+		case root != nil && prefix == "" && name == "profiles":
+			if err := p.profiles(root); err != nil {
+				return err
+			}
+		case isKey:
+			var raw json.RawMessage
+			if err := p.dec.Decode(&raw); err != nil {
+				return p.syntax(err)
+			}
+			if p.repo && !spec.repo {
+				return p.errAt(at, "%s cannot be set in repository config; set it in your user config", key)
+			}
+			if err := spec.check(raw); err != nil {
+				return p.errAt(at, "%s: %v", key, err)
+			}
+			out[key] = raw
+		case isGroup(schema, key):
+			if err := p.delim('{', key+" must be an object"); err != nil {
+				return err
+			}
+			if err := p.object(key+".", schema, out, nil); err != nil {
+				return err
+			}
+		default:
+			return p.errAt(at, "unknown key %q", key)
+		}
+	}
+	_, err := p.dec.Token()
+	return p.syntax(err)
+}
+
+func (p *fileParser) profiles(root *fileLayer) error {
+	if p.repo {
+		return p.errAt(p.dec.InputOffset(), "profiles cannot be set in repository config")
+	}
+	if err := p.delim('{', "profiles must be an object keyed by profile name"); err != nil {
+		return err
+	}
+	for p.dec.More() {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return p.syntax(err)
+		}
+		name, _ := tok.(string)
+		if !ValidProfileName(name) {
+			return p.errAt(p.dec.InputOffset(), "invalid profile name %q: %s", name, profileNameRule)
+		}
+		if err := p.delim('{', "profiles."+name+" must be an object"); err != nil {
+			return err
+		}
+		values := map[string]json.RawMessage{}
+		if err := p.object("", profileKeys, values, nil); err != nil {
+			var ferr *FileError
+			if errors.As(err, &ferr) {
+				ferr.Msg = "profiles." + name + ": " + ferr.Msg
+			}
+			return err
+		}
+		root.profiles[name] = values
+	}
+	_, err := p.dec.Token()
+	return p.syntax(err)
+}
+
+func (p *fileParser) delim(want json.Delim, msg string) error {
+	at := p.dec.InputOffset()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return p.syntax(err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return p.errAt(at, "%s", msg)
+	}
+	return nil
+}
+
+// syntax converts a decoder error into a FileError at the offending line.
+func (p *fileParser) syntax(err error) error {
+	if err == nil {
+		return nil
+	}
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		return p.errAt(serr.Offset, "invalid JSON: %v", serr)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return p.errAt(int64(len(p.src)), "invalid JSON: unexpected end of file")
+	}
+	return p.errAt(p.dec.InputOffset(), "invalid JSON: %v", err)
+}
+
+func (p *fileParser) errAt(offset int64, format string, args ...any) error {
+	if offset > int64(len(p.src)) {
+		offset = int64(len(p.src))
+	}
+	line := 1 + bytes.Count(p.src[:offset], []byte("\n"))
+	return &FileError{Path: p.path, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
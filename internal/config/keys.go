@@ -0,0 +1,216 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindDuration
+	kindList
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "boolean"
+	case kindInt:
+		return "non-negative integer"
+	case kindDuration:
+		return "duration such as \"500ms\" or \"30s\""
+	case kindList:
+		return "list of strings"
+	}
+	return "string"
+}
+
+type keySpec struct {
+	kind kind
+	// repo marks keys a repository's .codemint/config.json may set. Keys
+	// that pick the server, its certificates or what handles the token are
+	// user-only, so cloning a repository cannot redirect credentials.
+	repo bool
+	// values restricts a string key to a fixed set.
+	values []string
+}
+
+// keys lists every settable config key by its dotted JSON path.
+var keys = map[string]keySpec{
+	"base_url":                 {kind: kindString},
+	"profile":                  {kind: kindString},
+	"proxy":                    {kind: kindString},
+	"org":                      {kind: kindString, repo: true},
+	"offline":                  {kind: kindBool, repo: true},
+	"credential_helper":        {kind: kindString},
+	"retry.max_attempts":       {kind: kindInt, repo: true},
+	"retry.base_delay":         {kind: kindDuration, repo: true},
+	"retry.max_delay":          {kind: kindDuration, repo: true},
+	"retry.budget":             {kind: kindDuration, repo: true},
+	"cache.disabled":           {kind: kindBool, repo: true},
+	"cache.ttl":                {kind: kindDuration, repo: true},
+	"sync.concurrency":         {kind: kindInt, repo: true},
+	"tls.ca_file":              {kind: kindString},
+	"tls.cert_file":            {kind: kindString},
+	"tls.key_file":             {kind: kindString},
+	"tls.min_version":          {kind: kindString, values: []string{"1.2", "1.3"}},
+	"tls.insecure_skip_verify": {kind: kindBool},
+	"tls.pins":                 {kind: kindList},
+	"token_store.backend":      {kind: kindString, values: []string{"auto", "keychain", "encrypted", "file"}},
+	"token_store.key_file":     {kind: kindString},
+}
+
+// profileKeys are the keys a profile may override.
+var profileKeys = map[string]keySpec{
+	"base_url":                 keys["base_url"],
+	"proxy":                    keys["proxy"],
+	"org":                      keys["org"],
+	"tls.ca_file":              keys["tls.ca_file"],
+	"tls.cert_file":            keys["tls.cert_file"],
+	"tls.key_file":             keys["tls.key_file"],
+	"tls.min_version":          keys["tls.min_version"],
+	"tls.insecure_skip_verify": keys["tls.insecure_skip_verify"],
+	"tls.pins":                 keys["tls.pins"],
+}
+
+// Keys returns every config key in sorted order.
+func Keys() []string {
+	out := make([]string, 0, len(keys))
+	for k := range keys {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// EnvName is the environment variable that sets key, e.g. CODEMINT_TLS_CA_FILE.
+func EnvName(key string) string {
+	return "CODEMINT_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func lookupKey(key string) (keySpec, error) {
+	spec, ok := keys[key]
+	if !ok {
+		return keySpec{}, fmt.Errorf("unknown config key %q; see `codemint config list --all`", key)
+	}
+	return spec, nil
+}
+
+// isGroup reports whether prefix names an object holding keys, such as "tls".
+func isGroup(schema map[string]keySpec, prefix string) bool {
+	for k := range schema {
+		if strings.HasPrefix(k, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parse converts a command-line or environment value to JSON.
+func (s keySpec) parse(v string) (json.RawMessage, error) {
+	var out any
+	switch s.kind {
+	case kindString:
+		out = v
+	case kindBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("want %s, got %q", s.kind, v)
+		}
+		out = b
+	case kindInt:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("want %s, got %q", s.kind, v)
+		}
+		out = n
+	case kindDuration:
+		if _, err := time.ParseDuration(strings.TrimSpace(v)); err != nil {
+			return nil, fmt.Errorf("want %s, got %q", s.kind, v)
+		}
+		out = strings.TrimSpace(v)
+	case kindList:
+		list := []string{}
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		out = list
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return raw, s.check(raw)
+}
+
+// check validates a JSON value against the key's type.
+func (s keySpec) check(raw json.RawMessage) error {
+	var err error
+	switch s.kind {
+	case kindString:
+		var v string
+		if err = json.Unmarshal(raw, &v); err == nil && len(s.values) > 0 && !contains(s.values, v) {
+			return fmt.Errorf("want one of %s, got %q", strings.Join(s.values, ", "), v)
+		}
+	case kindBool:
+		var v bool
+		err = json.Unmarshal(raw, &v)
+	case kindInt:
+		var v int
+		if err = json.Unmarshal(raw, &v); err == nil && v < 0 {
+			err = fmt.Errorf("negative")
+		}
+	case kindDuration:
+		var v Duration
+		err = v.UnmarshalJSON(raw)
+	case kindList:
+		var v []string
+		err = json.Unmarshal(raw, &v)
+	}
+	if err != nil {
+		return fmt.Errorf("want %s, got %s", s.kind, string(raw))
+	}
+	return nil
+}
+
+// display renders a JSON value the way a user would type it.
+func display(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, ",")
+	}
+	return string(raw)
+}
+
+// isZero reports whether raw is an empty value, which a profile does not
+// apply.
+func isZero(raw json.RawMessage) bool {
+	switch strings.TrimSpace(string(raw)) {
+	case `""`, "false", "0", "[]", "null", "{}":
+		return true
+	}
+	return false
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
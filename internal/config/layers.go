@@ -0,0 +1,452 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/util"
+)
+
+// Layers a setting can come from, lowest precedence first.
+const (
+	LayerDefault = "default"
+	LayerUser    = "user"
+	LayerProfile = "profile"
+	LayerRepo    = "repo"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// EnvConfigDir overrides the directory holding the user config and tokens.
+const EnvConfigDir = "CODEMINT_CONFIG_DIR"
+
+// Origin says where a setting's value came from.
+type Origin struct {
+	Layer string
+	// Source is the file, profile, variable or flag within the layer.
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return o.Layer + " (" + o.Source + ")"
+}
+
+// Setting is one key's effective value.
+type Setting struct {
+	Key    string
+	Value  json.RawMessage
+	Origin Origin
+}
+
+// Display renders the value the way it would be typed to `config set`.
+func (s Setting) Display() string {
+	return display(s.Value)
+}
+
+// Resolved is the merged configuration together with where each setting
+// came from.
+type Resolved struct {
+	Config  Config
+	values  map[string]json.RawMessage
+	origins map[string]Origin
+}
+
+// Get returns the effective value of key; ok is false when no layer sets it.
+func (r *Resolved) Get(key string) (Setting, bool, error) {
+	if _, err := lookupKey(key); err != nil {
+		return Setting{}, false, err
+	}
+	raw, ok := r.values[key]
+	return Setting{Key: key, Value: raw, Origin: r.origins[key]}, ok, nil
+}
+
+// Settings returns every key some layer sets, sorted by key.
+func (r *Resolved) Settings() []Setting {
+	out := make([]Setting, 0, len(r.values))
+	for _, k := range Keys() {
+		if raw, ok := r.values[k]; ok {
+			out = append(out, Setting{Key: k, Value: raw, Origin: r.origins[k]})
+		}
+	}
+	return out
+}
+
+func (r *Resolved) set(key string, raw json.RawMessage, origin Origin) {
+	r.values[key] = raw
+	r.origins[key] = origin
+}
+
+// Dir is the directory holding the user config file and local credentials:
+// $CODEMINT_CONFIG_DIR, else $XDG_CONFIG_HOME/codemint, else
+// ~/.config/codemint.
+func Dir() (string, error) {
+	if dir := os.Getenv(EnvConfigDir); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "codemint"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "codemint"), nil
+}
+
+// RepoPath is the repository config file under root.
+func RepoPath(root string) string {
+	return filepath.Join(root, ".codemint", "config.json")
+}
+
+// Resolve merges, from lowest to highest precedence, the built-in defaults,
+// the user config, the active profile, the repository config, CODEMINT_*
+// environment variables and command-line flags.
+func Resolve(opts LoadOptions) (*Resolved, error) {
+	path, err := Path(opts.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	r := &Resolved{values: map[string]json.RawMessage{}, origins: map[string]Origin{}}
+	r.set("base_url", json.RawMessage(`"`+defaultBaseURL+`"`), Origin{Layer: LayerDefault})
+	r.set("profile", json.RawMessage(`"default"`), Origin{Layer: LayerDefault})
+
+	user, src, err := readLayer(path, false)
+	if err != nil {
+		return nil, err
+	}
+	applyFile(r, user.values, Origin{Layer: LayerUser, Source: path})
+
+	env, err := envLayer()
+	if err != nil {
+		return nil, err
+	}
+	flags, flagNames := opts.flagLayer()
+
+	profile, from := "default", ""
+	for _, layer := range []struct {
+		values map[string]json.RawMessage
+		source string
+	}{{r.values, path}, {env, EnvName("profile")}, {flags, "--profile"}} {
+		if raw, ok := layer.values["profile"]; ok {
+			_ = json.Unmarshal(raw, &profile)
+			from = layer.source
+		}
+	}
+	// The profile names token and lock files, so it must not reach outside
+	// the config directory.
+	if !ValidProfileName(profile) {
+		return nil, fmt.Errorf("%s: invalid profile name %q: %s", from, profile, profileNameRule)
+	}
+	for k, raw := range user.profiles[profile] {
+		if !isZero(raw) {
+			r.set(k, raw, Origin{Layer: LayerProfile, Source: profile})
+		}
+	}
+
+	if opts.RepoDir != "" {
+		repoPath := RepoPath(opts.RepoDir)
+		repo, _, err := readLayer(repoPath, true)
+		if err != nil {
+			return nil, err
+		}
+		applyFile(r, repo.values, Origin{Layer: LayerRepo, Source: filepath.Join(".codemint", "config.json")})
+	}
+	for k, raw := range env {
+		r.set(k, raw, Origin{Layer: LayerEnv, Source: EnvName(k)})
+	}
+	for k, raw := range flags {
+		r.set(k, raw, Origin{Layer: LayerFlag, Source: flagNames[k]})
+	}
+
+	cfg, err := build(r.values)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) > 0 {
+		var file struct {
+			Profiles map[string]ProfileConfig `json:"profiles"`
+		}
+		_ = json.Unmarshal(src, &file)
+		cfg.Profiles = file.Profiles
+	}
+	r.Config = cfg
+	return r, nil
+}
+
+// readLayer reads and validates a config file; a missing file is empty.
+func readLayer(path string, repo bool) (fileLayer, []byte, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fileLayer{}, nil, nil
+	}
+	if err != nil {
+		return fileLayer{}, nil, err
+	}
+	layer, err := parseFile(path, b, repo)
+	return layer, b, err
+}
+
+// applyFile copies a file's settings into r. Empty strings are what an
+// unset field is saved as, so they do not override lower layers.
+func applyFile(r *Resolved, values map[string]json.RawMessage, origin Origin) {
+	for k, raw := range values {
+		if s := strings.TrimSpace(string(raw)); s == `""` || s == "null" {
+			continue
+		}
+		r.set(k, raw, origin)
+	}
+}
+
+// envLayer reads CODEMINT_<KEY> for every key.
+func envLayer() (map[string]json.RawMessage, error) {
+	out := map[string]json.RawMessage{}
+	for _, k := range Keys() {
+		name := EnvName(k)
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		raw, err := keys[k].parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[k] = raw
+	}
+	return out, nil
+}
+
+// flagLayer returns the settings given on the command line and the flag
+// that set each one.
+func (o LoadOptions) flagLayer() (map[string]json.RawMessage, map[string]string) {
+	values := map[string]json.RawMessage{}
+	names := map[string]string{}
+	add := func(key, flag string, v any) {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		values[key] = raw
+		names[key] = "--" + flag
+	}
+	if o.BaseURLOverride != "" {
+		add("base_url", "base-url", o.BaseURLOverride)
+	}
+	if o.ProfileOverride != "" {
+		add("profile", "profile", o.ProfileOverride)
+	}
+	if o.OrgOverride != "" {
+		add("org", "org", o.OrgOverride)
+	}
+	if o.Offline {
+		add("offline", "offline", true)
+	}
+	t := o.TLSOverride
+	if t.CAFile != "" {
+		add("tls.ca_file", "ca-file", t.CAFile)
+	}
+	if t.CertFile != "" {
+		add("tls.cert_file", "client-cert", t.CertFile)
+	}
+	if t.KeyFile != "" {
+		add("tls.key_file", "client-key", t.KeyFile)
+	}
+	if t.MinVersion != "" {
+		add("tls.min_version", "tls-min-version", t.MinVersion)
+	}
+	if t.InsecureSkipVerify {
+		add("tls.insecure_skip_verify", "insecure-skip-verify", true)
+	}
+	if len(t.Pins) > 0 {
+		add("tls.pins", "tls-pin", t.Pins)
+	}
+	return values, names
+}
+
+// build turns dotted settings back into a Config.
+func build(values map[string]json.RawMessage) (Config, error) {
+	b, err := json.Marshal(nest(values))
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func nest(values map[string]json.RawMessage) map[string]any {
+	tree := map[string]any{}
+	for k, raw := range values {
+		put(tree, strings.Split(k, "."), raw)
+	}
+	return tree
+}
+
+// SetValue validates value for key and writes it to the config file at path,
+// keeping the file's other settings. repo limits key to those a repository
+// config may set.
+func SetValue(path, key, value string, repo bool) error {
+	spec, err := lookupKey(key)
+	if err != nil {
+		return err
+	}
+	if repo && !spec.repo {
+		return fmt.Errorf("%s cannot be set in repository config; set it in your user config", key)
+	}
+	if key == "profile" && !ValidProfileName(value) {
+		return fmt.Errorf("invalid profile name %q: %s", value, profileNameRule)
+	}
+	raw, err := spec.parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	tree, err := readTree(path, repo)
+	if err != nil {
+		return err
+	}
+	put(tree, strings.Split(key, "."), raw)
+	return writeTree(path, tree, repo)
+}
+
+// SetProfileValues validates values, keyed like profile overrides, and
+// writes them under profiles.<profile> in the user config at path, keeping
+// the file's other settings.
+func SetProfileValues(path, profile string, values map[string]string) error {
+	if !ValidProfileName(profile) {
+		return fmt.Errorf("invalid profile name %q: %s", profile, profileNameRule)
+	}
+	tree, err := readTree(path, false)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		spec, ok := profileKeys[key]
+		if !ok {
+			return fmt.Errorf("%s cannot be set for a profile", key)
+		}
+		raw, err := spec.parse(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		put(tree, append([]string{"profiles", profile}, strings.Split(key, ".")...), raw)
+	}
+	return writeTree(path, tree, false)
+}
+
+// UnsetProfileValue removes key from profiles.<profile> in the user config
+// at path and reports whether it was set there.
+func UnsetProfileValue(path, profile, key string) (bool, error) {
+	if _, ok := profileKeys[key]; !ok {
+		return false, fmt.Errorf("%s cannot be set for a profile", key)
+	}
+	tree, err := readTree(path, false)
+	if err != nil {
+		return false, err
+	}
+	if !remove(tree, append([]string{"profiles", profile}, strings.Split(key, ".")...)) {
+		return false, nil
+	}
+	return true, writeTree(path, tree, false)
+}
+
+// RemoveProfile deletes profiles.<profile> from the user config at path and
+// reports whether it was there.
+func RemoveProfile(path, profile string) (bool, error) {
+	tree, err := readTree(path, false)
+	if err != nil {
+		return false, err
+	}
+	if !remove(tree, []string{"profiles", profile}) {
+		return false, nil
+	}
+	return true, writeTree(path, tree, false)
+}
+
+// put sets the value at parts, creating the objects on the way.
+func put(node map[string]any, parts []string, raw json.RawMessage) {
+	for _, p := range parts[:len(parts)-1] {
+		child, ok := node[p].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			node[p] = child
+		}
+		node = child
+	}
+	node[parts[len(parts)-1]] = raw
+}
+
+// UnsetValue removes key from the config file at path and reports whether it
+// was set there.
+func UnsetValue(path, key string, repo bool) (bool, error) {
+	if _, err := lookupKey(key); err != nil {
+		return false, err
+	}
+	tree, err := readTree(path, repo)
+	if err != nil {
+		return false, err
+	}
+	if !remove(tree, strings.Split(key, ".")) {
+		return false, nil
+	}
+	return true, writeTree(path, tree, repo)
+}
+
+// remove deletes the value at parts, dropping objects it leaves empty.
+func remove(node map[string]any, parts []string) bool {
+	if len(parts) == 1 {
+		_, ok := node[parts[0]]
+		delete(node, parts[0])
+		return ok
+	}
+	child, ok := node[parts[0]].(map[string]any)
+	if !ok || !remove(child, parts[1:]) {
+		return false
+	}
+	if len(child) == 0 {
+		delete(node, parts[0])
+	}
+	return true
+}
+
+// readTree loads a config file for editing, refusing files that do not
+// validate so an edit never hides an earlier mistake.
+func readTree(path string, repo bool) (map[string]any, error) {
+	tree := map[string]any{}
+	_, b, err := readLayer(path, repo)
+	if err != nil || len(bytes.TrimSpace(b)) == 0 {
+		return tree, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// writeTree saves an edited config file. The user config may reference
+// credentials and stays private; a repository config is meant to be
+// committed.
+func writeTree(path string, tree map[string]any, repo bool) error {
+	b, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	perm, dirPerm := os.FileMode(0o600), os.FileMode(0o700)
+	if repo {
+		perm, dirPerm = 0o644, 0o755
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(path, append(b, '\n'), perm)
+}
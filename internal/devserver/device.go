@@ -0,0 +1,99 @@
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const deviceCodeTTL = 10 * time.Minute
+
+func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	deviceCode := randomHex(16)
+	raw := strings.ToUpper(randomHex(4))
+	userCode := raw[:4] + "-" + raw[4:]
+	s.mu.Lock()
+	s.devices[deviceCode] = &deviceLogin{userCode: userCode, expires: time.Now().Add(deviceCodeTTL)}
+	s.mu.Unlock()
+	verify := "http://" + r.Host + "/device"
+	writeJSON(w, r, http.StatusOK, api.DeviceCode{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verify,
+		VerificationURIComplete: verify + "?user_code=" + userCode,
+		ExpiresIn:               int(deviceCodeTTL.Seconds()),
+		Interval:                1,
+	})
+}
+
+func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		DeviceCode string `json:"deviceCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	s.mu.Lock()
+	d, ok := s.devices[in.DeviceCode]
+	switch {
+	case !ok || time.Now().After(d.expires):
+		delete(s.devices, in.DeviceCode)
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, api.DeviceExpiredToken, "device code expired")
+		return
+	case !d.approved:
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, api.DeviceAuthorizationPending, "waiting for the user to approve")
+		return
+	}
+	delete(s.devices, in.DeviceCode)
+	pair := s.issuePair()
+	s.mu.Unlock()
+	writeJSON(w, r, http.StatusOK, pair)
+}
+
+// handleDeviceApprove stands in for the web page where a signed-in user
+// enters the code shown by `auth login --device`.
+func (s *Server) handleDeviceApprove(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("user_code")
+	if code == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, `<form method="get"><label>Device code <input name="user_code"></label> <button>Approve</button></form>`)
+		return
+	}
+	if !s.ApproveDevice(code) {
+		http.Error(w, "unknown or expired code", http.StatusNotFound)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Device approved. Return to your terminal.")
+}
+
+// ApproveDevice approves the pending device login with the given user code.
+func (s *Server) ApproveDevice(userCode string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.devices {
+		if strings.EqualFold(d.userCode, strings.TrimSpace(userCode)) && time.Now().Before(d.expires) {
+			d.approved = true
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package devserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+// DefaultToken is accepted by every Server unless Fixtures.Token overrides it.
+const DefaultToken = "dev-token"
+
+// Fixtures seeds a Server. A fixture directory may contain:
+//
+//	user.json       the account returned by /api/auth/me
+//	items.json      an array of catalog items, including content
+//	orgs.json       an array of organizations
+//	org_items.json  private catalog items keyed by org slug
+//	token.txt       the bearer token to accept (default "dev-token")
+//
+// Every file is optional.
+type Fixtures struct {
+	User  api.AuthMeResponse
+	Items []api.CatalogItem
+	Orgs  []api.Organization
+	// OrgItems are private catalogs, keyed by org slug.
+	OrgItems map[string][]api.CatalogItem
+	Token    string
+}
+
+func LoadFixtures(dir string) (Fixtures, error) {
+	var f Fixtures
+	if err := readFixture(dir, "user.json", &f.User); err != nil {
+		return f, err
+	}
+	if err := readFixture(dir, "items.json", &f.Items); err != nil {
+		return f, err
+	}
+	if err := readFixture(dir, "orgs.json", &f.Orgs); err != nil {
+		return f, err
+	}
+	if err := readFixture(dir, "org_items.json", &f.OrgItems); err != nil {
+		return f, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "token.txt"))
+	switch {
+	case err == nil:
+		f.Token = strings.TrimSpace(string(b))
+	case !errors.Is(err, os.ErrNotExist):
+		return f, err
+	}
+	return f, nil
+}
+
+func readFixture(dir, name string, v any) error {
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+func (f Fixtures) withDefaults() Fixtures {
+	if f.Token == "" {
+		f.Token = DefaultToken
+	}
+	if f.User.ID == "" {
+		f.User = api.AuthMeResponse{ID: "u_dev", Email: "dev@example.com", Name: "Dev User"}
+	}
+	return f
+}
@@ -0,0 +1,116 @@
+package devserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const authCodeTTL = time.Minute
+
+// handleCLIAuth plays the browser half of `codemint auth login`: it
+// immediately approves the request and redirects to the loopback callback
+// with a one-time code bound to the PKCE challenge.
+func (s *Server) handleCLIAuth(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	port, err := strconv.Atoi(q.Get("port"))
+	if err != nil || port <= 0 || port > 65535 {
+		writeError(w, http.StatusBadRequest, "invalid_port", "port query parameter is required")
+		return
+	}
+	if q.Get("state") == "" || q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "state and an S256 code_challenge are required")
+		return
+	}
+	redirect := fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+	if v := q.Get("redirect_uri"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme != "http" || u.Port() != strconv.Itoa(port) {
+			writeError(w, http.StatusBadRequest, "invalid_request", "redirect_uri must be an http URL on the given port")
+			return
+		}
+		redirect = v
+	}
+	code := randomHex(16)
+	s.mu.Lock()
+	s.codes[code] = authCode{challenge: q.Get("code_challenge"), redirectURI: redirect, expires: time.Now().Add(authCodeTTL)}
+	s.mu.Unlock()
+	out := url.Values{}
+	out.Set("code", code)
+	out.Set("state", q.Get("state"))
+	http.Redirect(w, r, redirect+"?"+out.Encode(), http.StatusFound)
+}
+
+func (s *Server) handleCodeExchange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	var in api.AuthCodeExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.codes[in.Code]
+	delete(s.codes, in.Code)
+	sum := sha256.Sum256([]byte(in.CodeVerifier))
+	switch {
+	case !ok || time.Now().After(code.expires):
+		writeError(w, http.StatusBadRequest, "invalid_grant", "authorization code is invalid or expired")
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge:
+		writeError(w, http.StatusBadRequest, "invalid_grant", "code verifier does not match the challenge")
+	case in.RedirectURI != code.redirectURI:
+		writeError(w, http.StatusBadRequest, "invalid_grant", "redirect URI does not match")
+	default:
+		writeJSON(w, r, http.StatusOK, s.issuePair())
+	}
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	var in struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.refresh[in.RefreshToken]; !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_grant", "refresh token is invalid or already used")
+		return
+	}
+	delete(s.refresh, in.RefreshToken)
+	writeJSON(w, r, http.StatusOK, s.issuePair())
+}
+
+func (s *Server) handleRevokeRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	var in struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.refresh, in.RefreshToken)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package devserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+const (
+	// DefaultOIDCRequestToken authorizes /oidc/token, playing the part of
+	// GitHub's ACTIONS_ID_TOKEN_REQUEST_TOKEN.
+	DefaultOIDCRequestToken = "dev-oidc-request"
+	oidcAudience            = "codemint"
+	oidcIssuer              = "https://devserver.codemint.invalid"
+	oidcTokenTTL            = time.Hour
+)
+
+type idTokenClaims struct {
+	Issuer   string `json:"iss"`
+	Subject  string `json:"sub"`
+	Audience string `json:"aud"`
+	Expires  int64  `json:"exp"`
+}
+
+// IssueIDToken mints an HS256-signed ID token as a CI provider would, for
+// the dev server's own exchange endpoint to accept.
+func (s *Server) IssueIDToken(audience string) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims, _ := json.Marshal(idTokenClaims{
+		Issuer:   oidcIssuer,
+		Subject:  "repo:example/app:ref:refs/heads/main",
+		Audience: audience,
+		Expires:  time.Now().Add(5 * time.Minute).Unix(),
+	})
+	signed := header + "." + enc.EncodeToString(claims)
+	return signed + "." + enc.EncodeToString(s.signIDToken(signed))
+}
+
+func (s *Server) signIDToken(signed string) []byte {
+	mac := hmac.New(sha256.New, s.oidcKey)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// verifyIDToken checks the signature, audience and expiry of an ID token
+// from IssueIDToken.
+func (s *Server) verifyIDToken(tok string) (idTokenClaims, bool) {
+	var claims idTokenClaims
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return claims, false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.signIDToken(parts[0]+"."+parts[1])) {
+		return claims, false
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(body, &claims) != nil {
+		return claims, false
+	}
+	return claims, claims.Audience == oidcAudience && time.Now().Unix() < claims.Expires
+}
+
+// handleOIDCToken stands in for GitHub's ACTIONS_ID_TOKEN_REQUEST_URL.
+func (s *Server) handleOIDCToken(w http.ResponseWriter, r *http.Request) {
+	if bearer(r) != DefaultOIDCRequestToken {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "invalid OIDC request token")
+		return
+	}
+	audience := r.URL.Query().Get("audience")
+	if audience == "" {
+		audience = oidcAudience
+	}
+	writeJSON(w, r, http.StatusOK, map[string]string{"value": s.IssueIDToken(audience)})
+}
+
+func (s *Server) handleOIDCExchange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	var in api.OIDCExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	if _, ok := s.verifyIDToken(in.IDToken); !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "ID token is invalid, expired or has the wrong audience")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok := "dev-oidc-" + randomHex(16)
+	expires := time.Now().Add(oidcTokenTTL).UTC()
+	s.addToken(tok, expires)
+	writeJSON(w, r, http.StatusOK, api.CLIAuthCallbackPayload{Token: tok, ExpiresAt: expires.Format(time.RFC3339)})
+}
@@ -0,0 +1,414 @@
+package devserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+// Server is an in-memory stand-in for the CodeMint backend. It implements
+// every endpoint the CLI calls and is safe for concurrent use, so it can back
+// httptest servers in Go tests as well as `codemint dev-server`.
+type Server struct {
+	mu    sync.Mutex
+	user  api.AuthMeResponse
+	items map[string]api.CatalogItem
+	// orgItems holds each org's private catalog, served on top of items
+	// when a request names the org in api.OrgHeader.
+	orgItems map[string]map[string]api.CatalogItem
+	orgs     []api.Organization
+	// tokens maps accepted bearer tokens to what /api/auth/cli-token lists.
+	tokens map[string]*tokenRecord
+	// refresh maps unused refresh tokens to nothing; each is single use.
+	refresh map[string]struct{}
+	// devices holds pending device logins by device code.
+	devices map[string]*deviceLogin
+	// codes holds unredeemed authorization codes from /cli-auth.
+	codes map[string]authCode
+	// oidcKey signs the ID tokens the server issues as a stand-in CI
+	// provider.
+	oidcKey []byte
+	mux     *http.ServeMux
+}
+
+type authCode struct {
+	challenge   string
+	redirectURI string
+	expires     time.Time
+}
+
+type deviceLogin struct {
+	userCode string
+	expires  time.Time
+	approved bool
+}
+
+func New(f Fixtures) *Server {
+	f = f.withDefaults()
+	s := &Server{
+		user:     f.User,
+		items:    make(map[string]api.CatalogItem, len(f.Items)),
+		orgItems: make(map[string]map[string]api.CatalogItem),
+		orgs:     f.Orgs,
+		tokens:   make(map[string]*tokenRecord),
+		refresh:  make(map[string]struct{}),
+		devices:  make(map[string]*deviceLogin),
+		codes:    make(map[string]authCode),
+		oidcKey:  []byte(randomHex(32)),
+		mux:      http.NewServeMux(),
+	}
+	s.addToken(f.Token, time.Time{})
+	for _, it := range f.Items {
+		s.putItem(s.items, it)
+	}
+	for org, items := range f.OrgItems {
+		for _, it := range items {
+			s.putOrgItem(org, it)
+		}
+	}
+	s.mux.HandleFunc("/api/meta", s.handleMeta)
+	s.mux.HandleFunc("/api/auth/me", s.authed(s.handleMe))
+	s.mux.HandleFunc("/api/items/search", s.authed(s.handleSearch))
+	s.mux.HandleFunc("/api/catalog/resolve", s.authed(s.handleResolve))
+	s.mux.HandleFunc("/api/catalog/sync", s.authed(s.handleSync))
+	s.mux.HandleFunc("/api/org/my", s.authed(s.handleOrgs))
+	s.mux.HandleFunc("/api/auth/device/code", s.handleDeviceCode)
+	s.mux.HandleFunc("/api/auth/device/token", s.handleDeviceToken)
+	s.mux.HandleFunc("/device", s.handleDeviceApprove)
+	s.mux.HandleFunc("/api/auth/cli-token/exchange", s.handleCodeExchange)
+	s.mux.HandleFunc("/api/auth/cli-token", s.authed(s.handleTokens))
+	s.mux.HandleFunc("/api/auth/cli-token/", s.authed(s.handleRevokeToken))
+	s.mux.HandleFunc("/api/auth/refresh", s.handleRefresh)
+	s.mux.HandleFunc("/api/auth/refresh/revoke", s.handleRevokeRefresh)
+	s.mux.HandleFunc("/cli-auth", s.handleCLIAuth)
+	s.mux.HandleFunc("/oidc/token", s.handleOIDCToken)
+	s.mux.HandleFunc("/api/auth/oidc/exchange", s.handleOIDCExchange)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// PutItem adds or replaces a catalog item, for example to publish a new
+// version between `add` and `sync` in a test.
+func (s *Server) PutItem(it api.CatalogItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putItem(s.items, it)
+}
+
+// PutOrgItem adds an item to org's private catalog.
+func (s *Server) PutOrgItem(org string, it api.CatalogItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putOrgItem(org, it)
+}
+
+func (s *Server) putOrgItem(org string, it api.CatalogItem) {
+	if s.orgItems[org] == nil {
+		s.orgItems[org] = make(map[string]api.CatalogItem)
+	}
+	s.putItem(s.orgItems[org], it)
+}
+
+// DeleteItem removes an item so sync reports it as removed.
+func (s *Server) DeleteItem(catalogID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, catalogID)
+}
+
+// IssueToken mints a new bearer token the server will accept.
+func (s *Server) IssueToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken()
+}
+
+func (s *Server) issueToken() string {
+	tok := "dev-" + randomHex(16)
+	s.addToken(tok, time.Time{})
+	return tok
+}
+
+// RevokeToken makes the server reject tok, as if it had expired.
+func (s *Server) RevokeToken(tok string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, tok)
+}
+
+// RefreshTokens counts the refresh tokens the server still accepts.
+func (s *Server) RefreshTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.refresh)
+}
+
+// issuePair mints a token with a refresh token and expiry.
+func (s *Server) issuePair() api.CLIAuthCallbackPayload {
+	rt := "devrt-" + randomHex(16)
+	s.refresh[rt] = struct{}{}
+	tok := "dev-" + randomHex(16)
+	expires := time.Now().Add(30 * 24 * time.Hour).UTC()
+	s.addToken(tok, expires)
+	return api.CLIAuthCallbackPayload{
+		Token:        tok,
+		RefreshToken: rt,
+		ExpiresAt:    expires.Format(time.RFC3339),
+	}
+}
+
+func (s *Server) putItem(dst map[string]api.CatalogItem, it api.CatalogItem) {
+	if it.CatalogID == "" {
+		it.CatalogID = it.Type + ":" + it.Slug
+	}
+	if it.ID == "" {
+		it.ID = it.CatalogID
+	}
+	if it.Version == "" {
+		it.Version = "1.0.0"
+	}
+	if it.Checksum == "" && it.Content != "" {
+		sum := sha256.Sum256([]byte(it.Content))
+		it.Checksum = hex.EncodeToString(sum[:])
+	}
+	dst[it.CatalogID] = it
+}
+
+func (s *Server) authed(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tok := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		rec, ok := s.tokens[tok]
+		if ok {
+			rec.lastUsed = time.Now().UTC()
+		}
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "invalid or missing token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, api.Capabilities{
+		APIVersion:    api.APIVersion,
+		MinAPIVersion: 1,
+		MaxAPIVersion: api.APIVersion,
+		Features:      []string{api.FeatureOrgListWrapped, api.FeatureCatalogFields},
+	})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	user := s.user
+	s.mu.Unlock()
+	writeJSON(w, r, http.StatusOK, map[string]any{"user": user})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("q"))
+	itemType := q.Get("type")
+	var tags []string
+	if v := q.Get("tags"); v != "" {
+		tags = strings.Split(strings.ToLower(v), ",")
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	items, ok := s.catalog(w, r)
+	if !ok {
+		return
+	}
+	matches := make([]api.Item, 0)
+	for _, it := range items {
+		if itemType != "" && it.Type != itemType {
+			continue
+		}
+		if q.Get("slug") != "" && it.Slug != q.Get("slug") {
+			continue
+		}
+		score := matchScore(it, query, tags)
+		if score == 0 && (query != "" || len(tags) > 0) {
+			continue
+		}
+		matches = append(matches, api.Item{
+			ID:        it.ID,
+			Name:      it.Name,
+			Title:     it.Title,
+			Type:      it.Type,
+			Slug:      it.Slug,
+			CatalogID: it.CatalogID,
+			Version:   it.Version,
+			Checksum:  it.Checksum,
+			Tags:      it.Tags,
+			Score:     score,
+			ApplyMode: it.ApplyMode,
+			Globs:     it.Globs,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	start := (page - 1) * limit
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + limit
+	if end > len(matches) {
+		end = len(matches)
+	}
+	writeJSON(w, r, http.StatusOK, api.ItemsSearchResponse{Data: matches[start:end], Page: page, Limit: limit, Total: len(matches)})
+}
+
+func matchScore(it api.CatalogItem, query string, tags []string) int {
+	score := 0
+	for _, t := range it.Tags {
+		lt := strings.ToLower(t)
+		for _, want := range tags {
+			if lt == want {
+				score += 10
+			}
+		}
+		for _, word := range strings.Fields(query) {
+			if lt == word {
+				score += 5
+			}
+		}
+	}
+	if query != "" && (strings.Contains(strings.ToLower(it.Name), query) || strings.Contains(it.Slug, query)) {
+		score += 20
+	}
+	return score
+}
+
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	ref := strings.TrimPrefix(r.URL.Query().Get("ref"), "@")
+	itemType, slug, ok := strings.Cut(ref, "/")
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_ref", "ref must look like @rule/<slug>")
+		return
+	}
+	items, ok := s.catalog(w, r)
+	if !ok {
+		return
+	}
+	for _, it := range items {
+		if it.Type == itemType && it.Slug == slug {
+			writeJSON(w, r, http.StatusOK, it)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "not_found", "no catalog item @"+ref)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+	var in struct {
+		CatalogIDs []string `json:"catalogIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	items, ok := s.catalog(w, r)
+	if !ok {
+		return
+	}
+	byID := make(map[string]api.CatalogItem, len(items))
+	for _, it := range items {
+		byID[it.CatalogID] = it
+	}
+	out := make([]api.CatalogItem, 0, len(in.CatalogIDs))
+	for _, id := range in.CatalogIDs {
+		if it, ok := byID[id]; ok {
+			out = append(out, it)
+		}
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"items": out})
+}
+
+func (s *Server) handleOrgs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	orgs := append([]api.Organization{}, s.orgs...)
+	s.mu.Unlock()
+	writeJSON(w, r, http.StatusOK, api.OrgListResponse{Organizations: orgs})
+}
+
+// catalog returns the items visible to the request, sorted by catalog ID:
+// the public catalog plus the private catalog of the org in api.OrgHeader.
+// It answers 403 for an org the user does not belong to.
+func (s *Server) catalog(w http.ResponseWriter, r *http.Request) ([]api.CatalogItem, bool) {
+	org := r.Header.Get(api.OrgHeader)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if org != "" && !s.isMember(org) {
+		writeError(w, http.StatusForbidden, "forbidden", "not a member of organization "+org)
+		return nil, false
+	}
+	out := make([]api.CatalogItem, 0, len(s.items)+len(s.orgItems[org]))
+	for _, it := range s.items {
+		out = append(out, it)
+	}
+	for _, it := range s.orgItems[org] {
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CatalogID < out[j].CatalogID })
+	return out, true
+}
+
+func (s *Server) isMember(org string) bool {
+	for _, o := range s.orgs {
+		if o.Slug == org {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes v with an ETag so the CLI's response cache can revalidate.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "encode", err.Error())
+		return
+	}
+	sum := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", fmt.Sprintf("dev-%d", time.Now().UnixNano()))
+	if status == http.StatusOK && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(api.ErrorEnvelope{Error: api.ErrorBody{Code: code, Message: msg}})
+}
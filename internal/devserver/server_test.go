@@ -0,0 +1,132 @@
+package devserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+func testServer() *Server {
+	return New(Fixtures{Items: []api.CatalogItem{
+		{Type: "rule", Slug: "a", Name: "Alpha", Tags: []string{"go"}, Content: "a"},
+		{Type: "rule", Slug: "b", Name: "Beta", Tags: []string{"go"}, Content: "b"},
+		{Type: "skill", Slug: "c", Name: "Gamma", Tags: []string{"docs"}, Content: "c"},
+	}})
+}
+
+func get(t *testing.T, s *Server, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer "+DefaultToken)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSearchFiltersAndPages(t *testing.T) {
+	s := testServer()
+	rec := get(t, s, "/api/items/search?tags=go&limit=1&page=2")
+	var out api.ItemsSearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Total != 2 || len(out.Data) != 1 || out.Data[0].CatalogID != "rule:b" {
+		t.Fatalf("unexpected page: %+v", out)
+	}
+	if out.Data[0].Checksum == "" {
+		t.Fatalf("expected checksum to be derived from content")
+	}
+}
+
+func TestSearchRevalidatesWithETag(t *testing.T) {
+	s := testServer()
+	first := get(t, s, "/api/items/search?q=alpha")
+	req := httptest.NewRequest(http.MethodGet, "/api/items/search?q=alpha", nil)
+	req.Header.Set("Authorization", "Bearer "+DefaultToken)
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", rec.Code)
+	}
+}
+
+func TestResolveUnknownRef(t *testing.T) {
+	rec := get(t, testServer(), "/api/catalog/resolve?ref="+url.QueryEscape("@rule/missing"))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCLIAuthCodeExchangeChecksVerifier(t *testing.T) {
+	s := testServer()
+	verifier := "verifier-verifier-verifier-verifier-verifier"
+	sum := sha256.Sum256([]byte(verifier))
+	q := url.Values{"port": {"5123"}, "state": {"st"}, "code_challenge": {base64.RawURLEncoding.EncodeToString(sum[:])}, "code_challenge_method": {"S256"}}
+	rec := get(t, s, "/cli-auth?"+q.Encode())
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want 302", rec.Code)
+	}
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil || loc.Host != "127.0.0.1:5123" || loc.Query().Get("state") != "st" {
+		t.Fatalf("unexpected redirect %q", rec.Header().Get("Location"))
+	}
+	exchange := func(v string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(api.AuthCodeExchangeRequest{Code: loc.Query().Get("code"), CodeVerifier: v, RedirectURI: "http://127.0.0.1:5123/callback"})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/cli-token/exchange", strings.NewReader(string(body))))
+		return rec
+	}
+	if bad := exchange("wrong"); bad.Code != http.StatusBadRequest {
+		t.Fatalf("wrong verifier accepted: %d", bad.Code)
+	}
+
+	rec = get(t, s, "/cli-auth?"+q.Encode())
+	loc, _ = url.Parse(rec.Header().Get("Location"))
+	var tok api.CLIAuthCallbackPayload
+	if err := json.Unmarshal(exchange(verifier).Body.Bytes(), &tok); err != nil || tok.Token == "" {
+		t.Fatalf("exchange failed: %+v, %v", tok, err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+tok.Token)
+	me := httptest.NewRecorder()
+	s.ServeHTTP(me, req)
+	if me.Code != http.StatusOK {
+		t.Fatalf("issued token rejected: %d", me.Code)
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	s := testServer()
+	post := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+	var code api.DeviceCode
+	if err := json.Unmarshal(post("/api/auth/device/code", "{}").Body.Bytes(), &code); err != nil {
+		t.Fatal(err)
+	}
+	pending := post("/api/auth/device/token", `{"deviceCode":"`+code.DeviceCode+`"}`)
+	if pending.Code != http.StatusBadRequest || !strings.Contains(pending.Body.String(), api.DeviceAuthorizationPending) {
+		t.Fatalf("expected pending, got %d %s", pending.Code, pending.Body)
+	}
+	if approve := get(t, s, "/device?user_code="+code.UserCode); approve.Code != http.StatusOK {
+		t.Fatalf("approve status = %d", approve.Code)
+	}
+	var tok api.CLIAuthCallbackPayload
+	if err := json.Unmarshal(post("/api/auth/device/token", `{"deviceCode":"`+code.DeviceCode+`"}`).Body.Bytes(), &tok); err != nil || tok.Token == "" {
+		t.Fatalf("expected token, got %+v, %v", tok, err)
+	}
+	if again := post("/api/auth/device/token", `{"deviceCode":"`+code.DeviceCode+`"}`); !strings.Contains(again.Body.String(), api.DeviceExpiredToken) {
+		t.Fatalf("device code should be single use, got %s", again.Body)
+	}
+}
@@ -0,0 +1,83 @@
+package devserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+)
+
+type tokenRecord struct {
+	id       string
+	created  time.Time
+	lastUsed time.Time
+	expires  time.Time
+}
+
+func (s *Server) addToken(tok string, expires time.Time) {
+	s.tokens[tok] = &tokenRecord{id: "tok_" + randomHex(6), created: time.Now().UTC(), expires: expires}
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// handleTokens lists the caller's tokens on GET and revokes the calling token
+// on DELETE.
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := bearer(r)
+	switch r.Method {
+	case http.MethodGet:
+		out := api.CLITokenListResponse{Tokens: make([]api.CLIToken, 0, len(s.tokens))}
+		for tok, rec := range s.tokens {
+			out.Tokens = append(out.Tokens, api.CLIToken{
+				ID:         rec.id,
+				CreatedAt:  rec.created.Format(time.RFC3339),
+				LastUsedAt: formatTime(rec.lastUsed),
+				ExpiresAt:  formatTime(rec.expires),
+				Current:    tok == current,
+			})
+		}
+		sort.Slice(out.Tokens, func(i, j int) bool {
+			if out.Tokens[i].CreatedAt != out.Tokens[j].CreatedAt {
+				return out.Tokens[i].CreatedAt < out.Tokens[j].CreatedAt
+			}
+			return out.Tokens[i].ID < out.Tokens[j].ID
+		})
+		writeJSON(w, r, http.StatusOK, out)
+	case http.MethodDelete:
+		delete(s.tokens, current)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET or DELETE")
+	}
+}
+
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use DELETE")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/auth/cli-token/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tok, rec := range s.tokens {
+		if rec.id == id {
+			delete(s.tokens, tok)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "not_found", "no token with id "+id)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,80 @@
+package httpcache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/util"
+)
+
+// Objects live beside cached responses and hold decoded records, such as the
+// catalog items offline mode answers from.
+type object struct {
+	ID       string          `json:"id"`
+	StoredAt time.Time       `json:"storedAt"`
+	Value    json.RawMessage `json:"value"`
+}
+
+func (s *Store) bucketDir(bucket string) string {
+	return filepath.Join(s.dir, "objects", bucket)
+}
+
+func (s *Store) PutObject(bucket, id string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(object{ID: id, StoredAt: time.Now().UTC(), Value: raw})
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(filepath.Join(s.bucketDir(bucket), Key(id)+".json"), b, 0o600)
+}
+
+// GetObject decodes the object into v and reports when it was stored.
+func (s *Store) GetObject(bucket, id string, v any) (time.Time, bool) {
+	b, err := os.ReadFile(filepath.Join(s.bucketDir(bucket), Key(id)+".json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var obj object
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(obj.Value, v); err != nil {
+		return time.Time{}, false
+	}
+	return obj.StoredAt, true
+}
+
+// EachObject calls fn with the raw value of every object in the bucket.
+// Unreadable entries are skipped.
+func (s *Store) EachObject(bucket string, fn func(raw json.RawMessage, storedAt time.Time) error) error {
+	entries, err := os.ReadDir(s.bucketDir(bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(s.bucketDir(bucket), e.Name()))
+		if err != nil {
+			continue
+		}
+		var obj object
+		if err := json.Unmarshal(b, &obj); err != nil {
+			continue
+		}
+		if err := fn(obj.Value, obj.StoredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/util"
+)
+
+// Entry is a cached response body plus the validators needed to revalidate it.
+type Entry struct {
+	Key          string          `json:"key"`
+	URL          string          `json:"url"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"lastModified,omitempty"`
+	StoredAt     time.Time       `json:"storedAt"`
+	Body         json.RawMessage `json:"body"`
+}
+
+func (e Entry) Age() time.Duration {
+	return time.Since(e.StoredAt)
+}
+
+type Info struct {
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Bytes   int64     `json:"bytes"`
+	Oldest  time.Time `json:"oldest,omitempty"`
+	Newest  time.Time `json:"newest,omitempty"`
+}
+
+type Store struct {
+	dir string
+}
+
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "codemint"), nil
+}
+
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) responsesDir() string {
+	return filepath.Join(s.dir, "http")
+}
+
+// Key derives a stable file-safe key from the request identity.
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) Get(key string) (Entry, bool) {
+	b, err := os.ReadFile(filepath.Join(s.responsesDir(), key+".json"))
+	if err != nil {
+		return Entry{}, false
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil || len(e.Body) == 0 {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+func (s *Store) Put(e Entry) error {
+	if e.StoredAt.IsZero() {
+		e.StoredAt = time.Now().UTC()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(filepath.Join(s.responsesDir(), e.Key+".json"), b, 0o600)
+}
+
+func (s *Store) Info() (Info, error) {
+	info := Info{Dir: s.dir}
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		info.Entries++
+		info.Bytes += fi.Size()
+		mod := fi.ModTime().UTC()
+		if info.Oldest.IsZero() || mod.Before(info.Oldest) {
+			info.Oldest = mod
+		}
+		if mod.After(info.Newest) {
+			info.Newest = mod
+		}
+		return nil
+	})
+	return info, err
+}
+
+// Clear removes every cached entry and returns how many were deleted.
+func (s *Store) Clear() (int, error) {
+	info, err := s.Info()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		return 0, err
+	}
+	return info.Entries, nil
+}
@@ -14,6 +14,11 @@ import (
 type InstallResult struct {
 	Path     string `json:"path"`
 	Checksum string `json:"checksum"`
+	// Existed records that Path was there before Install, even if empty.
+	Existed bool `json:"existed,omitempty"`
+	// Backup holds the previous content of Path, or is empty when there was
+	// no content to keep.
+	Backup string `json:"backup,omitempty"`
 }
 
 type Manager struct {
@@ -111,16 +116,38 @@ func (m *Manager) Install(item api.CatalogItem, tool string) (InstallResult, err
 	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
 		return InstallResult{}, err
 	}
-	if existing, err := os.ReadFile(path); err == nil && len(existing) > 0 {
-		backup := m.BackupPath(tool, item.Type, item.Slug)
-		if err := util.AtomicWriteFile(backup, existing, 0o644); err != nil {
-			return InstallResult{}, err
+	res := InstallResult{Path: path, Checksum: util.SHA256Hex([]byte(content))}
+	if existing, err := os.ReadFile(path); err == nil {
+		res.Existed = true
+		if len(existing) > 0 {
+			res.Backup = m.BackupPath(tool, item.Type, item.Slug)
+			if err := util.AtomicWriteFile(res.Backup, existing, 0o644); err != nil {
+				return InstallResult{}, err
+			}
 		}
 	}
 	if err := util.AtomicWriteFile(path, []byte(content), 0o644); err != nil {
 		return InstallResult{}, err
 	}
-	return InstallResult{Path: path, Checksum: util.SHA256Hex([]byte(content))}, nil
+	return res, nil
+}
+
+// Rollback undoes an Install: the previous content is restored from its
+// backup, an empty file is restored empty, and a file Install created is
+// removed.
+func (m *Manager) Rollback(res InstallResult) error {
+	switch {
+	case !res.Existed:
+		_, err := m.RemovePath(res.Path)
+		return err
+	case res.Backup == "":
+		return util.AtomicWriteFile(res.Path, nil, 0o644)
+	}
+	prev, err := os.ReadFile(res.Backup)
+	if err != nil {
+		return fmt.Errorf("rollback %s: %w", res.Path, err)
+	}
+	return util.AtomicWriteFile(res.Path, prev, 0o644)
 }
 
 func (m *Manager) RemovePath(path string) (string, error) {
@@ -1,9 +1,12 @@
 package install
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/codemint/codemint-cli/internal/api"
 	"github.com/codemint/codemint-cli/internal/tooling"
 )
 
@@ -30,3 +33,56 @@ func TestItemPathByTool(t *testing.T) {
 		}
 	}
 }
+
+func TestRollbackRestoresPreviousContent(t *testing.T) {
+	m := NewManager(t.TempDir())
+	item := api.CatalogItem{Type: "rule", Slug: "safe-api", Content: "v1"}
+	first, err := m.Install(item, tooling.ToolCodex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Content = "v2"
+	second, err := m.Install(item, tooling.ToolCodex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Backup == "" {
+		t.Fatalf("expected a backup when replacing an existing file")
+	}
+	if err := m.Rollback(second); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(second.Path)
+	if !strings.Contains(string(b), "v1") {
+		t.Fatalf("rollback left %q", b)
+	}
+	if err := m.Rollback(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected fresh install to be removed, stat err = %v", err)
+	}
+}
+
+func TestRollbackKeepsAnEmptyFile(t *testing.T) {
+	m := NewManager(t.TempDir())
+	item := api.CatalogItem{Type: "rule", Slug: "safe-api", Content: "v1"}
+	path := m.ItemPath(tooling.ToolCodex, item.Type, item.Slug)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := m.Install(item, tooling.ToolCodex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rollback(res); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || len(b) != 0 {
+		t.Fatalf("expected the empty file back, got %q, %v", b, err)
+	}
+}
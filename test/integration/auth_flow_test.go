@@ -13,3 +13,20 @@ func TestRedactToken(t *testing.T) {
 		t.Fatalf("expected token to be redacted")
 	}
 }
+
+func TestRedactTokenQueryAndBody(t *testing.T) {
+	cases := map[string]string{
+		"GET http://127.0.0.1:5000/callback?token=abc123&expiresAt=2026":   "GET http://127.0.0.1:5000/callback?token=[REDACTED]&expiresAt=2026",
+		`{"token":"abc123","expiresAt":"2026"}`:                            `{"token":"[REDACTED]","expiresAt":"2026"}`,
+		"GET http://127.0.0.1:5000/callback?code=ac-1&state=s1":            "GET http://127.0.0.1:5000/callback?code=[REDACTED]&state=s1",
+		`{"code":"ac-1","codeVerifier":"v-123","redirectUri":"http://x"}`:  `{"code":"[REDACTED]","codeVerifier":"[REDACTED]","redirectUri":"http://x"}`,
+		"grant_type=x&code_verifier=v-123&device_code=dc-9&user_code=ABCD": "grant_type=x&code_verifier=[REDACTED]&device_code=[REDACTED]&user_code=ABCD",
+		`{"deviceCode":"dc-9","userCode":"ABCD-1234"}`:                     `{"deviceCode":"[REDACTED]","userCode":"ABCD-1234"}`,
+		`{"refreshToken":"r-1"}`:                                           `{"refreshToken":"[REDACTED]"}`,
+	}
+	for in, want := range cases {
+		if got := auth.RedactToken(in); got != want {
+			t.Fatalf("RedactToken(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
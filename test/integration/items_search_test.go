@@ -19,7 +19,7 @@ func TestItemsSearch(t *testing.T) {
 		return &http.Response{
 			StatusCode: http.StatusOK,
 			Header:     http.Header{"Content-Type": []string{"application/json"}},
-			Body:       io.NopCloser(strings.NewReader(`{"items":[{"id":"i1","name":"Alpha","type":"pkg","tags":["x"],"score":10}],"page":1,"limit":20,"total":1}`)),
+			Body:       io.NopCloser(strings.NewReader(`{"data":[{"id":"i1","name":"Alpha","type":"pkg","tags":["x"],"score":10}],"page":1,"limit":20,"total":1}`)),
 		}, nil
 	})
 
@@ -28,7 +28,7 @@ func TestItemsSearch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ItemsSearch error: %v", err)
 	}
-	if len(resp.Items) != 1 || resp.Items[0].ID != "i1" {
-		t.Fatalf("unexpected response: %+v", resp.Items)
+	if len(resp.Data) != 1 || resp.Data[0].ID != "i1" {
+		t.Fatalf("unexpected response: %+v", resp.Data)
 	}
 }
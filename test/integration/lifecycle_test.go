@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+	"github.com/codemint/codemint-cli/internal/install"
+	"github.com/codemint/codemint-cli/internal/manifest"
+	"github.com/codemint/codemint-cli/internal/tooling"
+)
+
+func TestAddSyncRemoveLifecycle(t *testing.T) {
+	fixtures, err := devserver.LoadFixtures("../fixtures/devserver")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	srv := devserver.New(fixtures)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	reqCtx := context.Background()
+	tok := devserver.DefaultToken
+	c := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, UserAgent: "test/1"})
+
+	me, err := c.AuthMe(reqCtx, tok)
+	if err != nil || me.Email != "dev@example.com" {
+		t.Fatalf("AuthMe = %+v, %v", me, err)
+	}
+	found, err := c.ItemsSearch(reqCtx, tok, api.ItemsSearchRequest{Q: "go", Type: "rule"})
+	if err != nil || len(found.Data) != 1 || found.Data[0].Slug != "go-errors" {
+		t.Fatalf("ItemsSearch = %+v, %v", found, err)
+	}
+
+	// add
+	item, err := c.CatalogGetByRef(reqCtx, tok, "rule", "go-errors")
+	if err != nil {
+		t.Fatalf("CatalogGetByRef: %v", err)
+	}
+	root := t.TempDir()
+	mgr := install.NewManager(root)
+	store := manifest.New(root)
+	res, err := mgr.Install(*item, tooling.ToolCursor)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	mf := manifest.File{Installed: []manifest.Item{{
+		CatalogID: item.CatalogID, Ref: "@rule/go-errors", Type: item.Type, Slug: item.Slug,
+		Tool: tooling.ToolCursor, Version: item.Version, Checksum: item.Checksum, Path: res.Path,
+	}}}
+	if err := store.Save(mf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// publish a new version, then sync
+	updated := *item
+	updated.Version = "1.1.0"
+	updated.Content = "Wrap errors with %w and add context.\n"
+	updated.Checksum = ""
+	srv.PutItem(updated)
+	syncReq := api.CatalogSyncRequest{Items: []api.CatalogSyncItem{{CatalogID: item.CatalogID, Version: item.Version, Checksum: item.Checksum}}}
+	out, err := c.CatalogSync(reqCtx, tok, syncReq)
+	if err != nil {
+		t.Fatalf("CatalogSync: %v", err)
+	}
+	if len(out.Results) != 1 || out.Results[0].LatestVersion != "1.1.0" {
+		t.Fatalf("sync results = %+v", out.Results)
+	}
+	if _, err := mgr.Install(out.Results[0].LatestItem, tooling.ToolCursor); err != nil {
+		t.Fatalf("Install update: %v", err)
+	}
+	b, err := os.ReadFile(res.Path)
+	if err != nil || !strings.Contains(string(b), "add context") {
+		t.Fatalf("installed content = %q, %v", b, err)
+	}
+
+	// the item disappears upstream
+	srv.DeleteItem(item.CatalogID)
+	out, err = c.CatalogSync(reqCtx, tok, syncReq)
+	if err != nil || len(out.Results) != 1 || !out.Results[0].Removed {
+		t.Fatalf("sync after delete = %+v, %v", out, err)
+	}
+
+	// remove
+	if _, err := mgr.RemovePath(res.Path); err != nil {
+		t.Fatalf("RemovePath: %v", err)
+	}
+	if err := store.Save(manifest.File{}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(res.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, stat err = %v", res.Path, err)
+	}
+}
+
+func TestDevServerRejectsUnknownToken(t *testing.T) {
+	ts := httptest.NewServer(devserver.New(devserver.Fixtures{}))
+	defer ts.Close()
+
+	c := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, UserAgent: "test/1"})
+	_, err := c.AuthMe(context.Background(), "nope")
+	if !api.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+)
+
+func TestOrgScopedCatalog(t *testing.T) {
+	fixtures, err := devserver.LoadFixtures("../fixtures/devserver")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	ts := httptest.NewServer(devserver.New(fixtures))
+	defer ts.Close()
+	reqCtx := context.Background()
+	client := func(org string) *api.Client {
+		return api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, UserAgent: "test/1", Org: org})
+	}
+
+	if _, err := client("").CatalogGetByRef(reqCtx, devserver.DefaultToken, "rule", "acme-review"); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("private item visible without org: %v", err)
+	}
+	item, err := client("acme").CatalogGetByRef(reqCtx, devserver.DefaultToken, "rule", "acme-review")
+	if err != nil || item.CatalogID != "rule:acme-review" {
+		t.Fatalf("CatalogGetByRef in acme = %+v, %v", item, err)
+	}
+	found, err := client("acme").ItemsSearch(reqCtx, devserver.DefaultToken, api.ItemsSearchRequest{Q: "go", Type: "rule"})
+	if err != nil || len(found.Data) != 1 {
+		t.Fatalf("public items missing in org scope: %+v, %v", found, err)
+	}
+	if _, err := client("initech").ItemsSearch(reqCtx, devserver.DefaultToken, api.ItemsSearchRequest{Q: "go"}); !errors.Is(err, api.ErrForbidden) {
+		t.Fatalf("expected forbidden for non-member org, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codemint/codemint-cli/internal/api"
+	"github.com/codemint/codemint-cli/internal/devserver"
+)
+
+func TestListAndRevokeCLITokens(t *testing.T) {
+	srv := devserver.New(devserver.Fixtures{})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	reqCtx := context.Background()
+	c := api.NewClient(api.ClientOptions{BaseURL: ts.URL, Timeout: 2 * time.Second, UserAgent: "test/1"})
+	laptop := srv.IssueToken()
+	srv.IssueToken()
+
+	list, err := c.ListCLITokens(reqCtx, laptop)
+	if err != nil || len(list.Tokens) != 3 {
+		t.Fatalf("ListCLITokens = %+v, %v", list, err)
+	}
+	var current, other string
+	for _, tok := range list.Tokens {
+		if tok.Current {
+			current = tok.ID
+		} else if other == "" {
+			other = tok.ID
+		}
+	}
+	if current == "" || other == "" {
+		t.Fatalf("expected one current token: %+v", list.Tokens)
+	}
+
+	if err := c.RevokeCLIToken(reqCtx, laptop, other); err != nil {
+		t.Fatalf("RevokeCLIToken: %v", err)
+	}
+	if err := c.RevokeCLIToken(reqCtx, laptop, other); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("second revoke = %v, want not found", err)
+	}
+	list, err = c.ListCLITokens(reqCtx, laptop)
+	if err != nil || len(list.Tokens) != 2 {
+		t.Fatalf("after revoke: %+v, %v", list, err)
+	}
+
+	// logout --revoke invalidates the calling token itself.
+	if err := c.RevokeCurrentCLIToken(reqCtx, laptop); err != nil {
+		t.Fatalf("RevokeCurrentCLIToken: %v", err)
+	}
+	if _, err := c.AuthMe(reqCtx, laptop); !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("revoked token still accepted: %v", err)
+	}
+}
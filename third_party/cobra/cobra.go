@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
 type Command struct {
@@ -38,6 +39,12 @@ func (c *Command) Execute() error {
 	return c.execute(args)
 }
 
+// ExecuteContext runs the command tree with ctx available from Context.
+func (c *Command) ExecuteContext(ctx context.Context) error {
+	c.ctx = ctx
+	return c.Execute()
+}
+
 func (c *Command) execute(args []string) error {
 	if wantsHelp(args) {
 		fmt.Println(c.help())
@@ -185,6 +192,38 @@ func (f *FlagSet) IntVar(p *int, name string, value int, usage string) {
 	f.fs.IntVar(p, name, value, usage)
 }
 
+func (f *FlagSet) DurationVar(p *time.Duration, name string, value time.Duration, usage string) {
+	f.fs.DurationVar(p, name, value, usage)
+}
+
+func (f *FlagSet) StringVarP(p *string, name, shorthand, value, usage string) {
+	f.fs.StringVar(p, name, value, usage)
+	if shorthand != "" {
+		f.fs.StringVar(p, shorthand, value, usage)
+	}
+}
+
+func (f *FlagSet) BoolVarP(p *bool, name, shorthand string, value bool, usage string) {
+	f.fs.BoolVar(p, name, value, usage)
+	if shorthand != "" {
+		f.fs.BoolVar(p, shorthand, value, usage)
+	}
+}
+
+// StringArrayVarP collects every occurrence of the flag, without splitting
+// values on commas.
+func (f *FlagSet) StringArrayVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	*p = append([]string(nil), value...)
+	set := func(v string) error {
+		*p = append(*p, v)
+		return nil
+	}
+	f.fs.Func(name, usage, set)
+	if shorthand != "" {
+		f.fs.Func(shorthand, usage, set)
+	}
+}
+
 func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
 	joined := ""
 	if len(value) > 0 {
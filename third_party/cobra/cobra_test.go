@@ -49,3 +49,26 @@ func TestNormalizeInterspersedArgsRespectsDoubleDash(t *testing.T) {
 		t.Fatalf("flag after -- should not be parsed")
 	}
 }
+
+func TestShorthandAndArrayFlags(t *testing.T) {
+	var method string
+	var fields []string
+	var include bool
+	cmd := &Command{
+		Use:  "api",
+		RunE: func(_ *Command, _ []string) error { return nil },
+	}
+	cmd.Flags().StringVarP(&method, "method", "X", "GET", "")
+	cmd.Flags().StringArrayVarP(&fields, "field", "f", nil, "")
+	cmd.Flags().BoolVarP(&include, "include", "i", false, "")
+
+	if err := cmd.execute([]string{"/api/x", "-X", "POST", "-f", "a=1,2", "--field", "b=3", "-i"}); err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+	if method != "POST" || !include {
+		t.Fatalf("unexpected method=%q include=%v", method, include)
+	}
+	if len(fields) != 2 || fields[0] != "a=1,2" || fields[1] != "b=3" {
+		t.Fatalf("unexpected fields: %#v", fields)
+	}
+}